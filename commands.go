@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/topi314/esphome-dashboard/dashboard"
+)
+
+// loadServer loads the config & creates a server for the commands which don't serve requests.
+// Logs are written to stderr to keep stdout free for the output of the command.
+func loadServer(cfgPath string) (*dashboard.Server, error) {
+	path, err := resolveConfigPath(cfgPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := dashboard.LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = setupLogger(cfg.Log, os.Stderr); err != nil {
+		return nil, err
+	}
+
+	version, goVersion := buildVersion()
+	return dashboard.New(cfg, version, goVersion, templatesFS(cfg)), nil
+}
+
+func render(args []string) int {
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	cfgPath := configFlag(flags)
+	dashboardName := flags.String("dashboard", "", "name of the dashboard to render")
+	page := flags.String("page", "0", "index, name or alias of the page to render")
+	format := flags.String("format", "png", "output format (html, png, jpeg, bmp)")
+	output := flags.String("o", "-", "file to write the rendered page to, - for stdout")
+	timeout := flags.Duration("timeout", time.Minute, "maximum duration of the render")
+	_ = flags.Parse(args)
+
+	if *dashboardName == "" {
+		fmt.Fprintln(os.Stderr, "missing -dashboard")
+		flags.Usage()
+		return 2
+	}
+
+	s, err := loadServer(*cfgPath)
+	if err != nil {
+		slog.Error("Error while loading config", slog.Any("err", err))
+		return 1
+	}
+	defer func() {
+		_ = s.Stop(context.Background())
+	}()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	ctx, cancel = context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	content, _, err := s.Render(ctx, *dashboardName, *page, *format)
+	if err != nil {
+		slog.Error("Error while rendering page", slog.Any("err", err))
+		return 1
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			slog.Error("Error while creating output file", slog.Any("err", err))
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if _, err = io.Copy(w, content); err != nil {
+		slog.Error("Error while writing rendered page", slog.Any("err", err))
+		return 1
+	}
+	return 0
+}
+
+func validate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	cfgPath := configFlag(flags)
+	_ = flags.Parse(args)
+
+	path, err := resolveConfigPath(*cfgPath)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	var issues []dashboard.ValidationIssue
+	s, err := loadServer(path)
+	if err != nil {
+		// the dashboards can't be validated without a valid config
+		if issues = dashboard.ValidateConfig(path); len(issues) == 0 {
+			issues = append(issues, dashboard.ValidationIssue{File: path, Message: err.Error()})
+		}
+	} else {
+		issues = s.Validate()
+	}
+
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	if len(issues) > 0 {
+		fmt.Fprintf(os.Stderr, "found %d issue(s)\n", len(issues))
+		return 1
+	}
+	return 0
+}
+
+func list(args []string) int {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	cfgPath := configFlag(flags)
+	jsonOutput := flags.Bool("json", false, "print the dashboards as JSON")
+	_ = flags.Parse(args)
+
+	s, err := loadServer(*cfgPath)
+	if err != nil {
+		slog.Error("Error while loading config", slog.Any("err", err))
+		return 1
+	}
+
+	dashboards, err := s.ListDashboards()
+	if err != nil {
+		slog.Error("Error while listing dashboards", slog.Any("err", err))
+		return 1
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(dashboards); err != nil {
+			slog.Error("Error while writing dashboards", slog.Any("err", err))
+			return 1
+		}
+		return 0
+	}
+
+	for _, d := range dashboards {
+		if d.Error != "" {
+			fmt.Printf("%s (error: %s)\n", d.Name, d.Error)
+			continue
+		}
+		fmt.Printf("%s (%dx%d)\n", d.Name, d.Width, d.Height)
+		for _, page := range d.Pages {
+			line := fmt.Sprintf("  %d: %s (%s)", page.Index, page.Name, page.Path)
+			if len(page.Aliases) > 0 {
+				line += " aliases: " + strings.Join(page.Aliases, ", ")
+			}
+			fmt.Println(line)
+		}
+	}
+	return 0
+}
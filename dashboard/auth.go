@@ -0,0 +1,114 @@
+package dashboard
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	// APIKeyHeader can be used to pass an API key instead of the Authorization header or the token query parameter.
+	APIKeyHeader = "X-API-Key"
+
+	// internalTokenCookie is used by the headless chrome to authenticate against the server while rendering pages.
+	internalTokenCookie = "dashboard_internal_token"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Name       string
+	Dashboards []string
+}
+
+// CanAccess returns whether the principal is allowed to access the given dashboard.
+// An empty dashboard refers to global routes which are only accessible by principals without a dashboard scope.
+func (p Principal) CanAccess(dashboard string) bool {
+	if len(p.Dashboards) == 0 {
+		return true
+	}
+	if dashboard == "" {
+		return false
+	}
+	return slices.Contains(p.Dashboards, dashboard)
+}
+
+func newInternalToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// requestToken returns the API key of the request from the Authorization header, the X-API-Key header or the token query parameter.
+func requestToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	if token := r.Header.Get(APIKeyHeader); token != "" {
+		return strings.TrimSpace(token)
+	}
+	return r.URL.Query().Get("token")
+}
+
+func secureCompare(a string, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// authenticate returns the principal of the request or false if the request could not be authenticated.
+func (s *Server) authenticate(r *http.Request, cfg *AuthConfig) (Principal, bool) {
+	if cookie, err := r.Cookie(internalTokenCookie); err == nil && secureCompare(cookie.Value, s.internalToken) {
+		return Principal{Name: "internal"}, true
+	}
+
+	if token := requestToken(r); token != "" {
+		for _, key := range cfg.Keys {
+			if secureCompare(token, key.Key) {
+				return Principal{Name: key.Name, Dashboards: key.Dashboards}, true
+			}
+		}
+		return Principal{}, false
+	}
+
+	if username, password, ok := r.BasicAuth(); ok {
+		for _, user := range cfg.Users {
+			if secureCompare(username, user.Username) && secureCompare(password, user.Password) {
+				return Principal{Name: user.Username, Dashboards: user.Dashboards}, true
+			}
+		}
+	}
+
+	return Principal{}, false
+}
+
+// auth wraps the handler with the configured authentication and dashboard scoping.
+// The handler is served unauthenticated if no auth is configured.
+func (s *Server) auth(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := s.config().Auth
+		if cfg == nil {
+			handler(w, r)
+			return
+		}
+
+		principal, ok := s.authenticate(r, cfg)
+		if !ok {
+			if len(cfg.Users) > 0 {
+				w.Header().Set("WWW-Authenticate", `Basic realm="Dashboard", charset="UTF-8"`)
+			}
+			Error(r.Context(), w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		dashboard := r.PathValue("dashboard")
+		if !principal.CanAccess(dashboard) {
+			slog.WarnContext(r.Context(), "access denied", slog.String("principal", principal.Name), slog.String("dashboard", dashboard))
+			Error(r.Context(), w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		handler(w, r)
+	}
+}
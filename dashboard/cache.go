@@ -0,0 +1,71 @@
+package dashboard
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/topi314/esphome-dashboard/dashboard/metrics"
+)
+
+func newCache(requests *metrics.CounterVec) *cache {
+	return &cache{
+		entries:  make(map[string]cacheEntry),
+		requests: requests,
+	}
+}
+
+type cacheEntry struct {
+	value     any
+	expiresAt time.Time
+}
+
+// cache is a simple in-memory cache used to avoid fetching external data sources on every render.
+type cache struct {
+	mu       sync.Mutex
+	entries  map[string]cacheEntry
+	requests *metrics.CounterVec
+}
+
+func (c *cache) get(key string) (any, bool) {
+	value, ok := c.lookup(key)
+	if c.requests != nil {
+		// keys are prefixed with the kind of the data source, e.g. "feed:"
+		kind, _, _ := strings.Cut(key, ":")
+		result := "miss"
+		if ok {
+			result = "hit"
+		}
+		c.requests.Inc(kind, result)
+	}
+	return value, ok
+}
+
+func (c *cache) lookup(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *cache) set(key string, value any, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = cacheEntry{
+		value:     value,
+		expiresAt: time.Now().Add(ttl),
+	}
+}
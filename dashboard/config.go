@@ -3,29 +3,270 @@ package dashboard
 import (
 	"fmt"
 	"log/slog"
+	"maps"
+	"net"
+	"net/netip"
+	"net/url"
 	"os"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
-
-	"github.com/BurntSushi/toml"
+	"time"
 )
 
 func LoadConfig(cfgPath string) (Config, error) {
-	file, err := os.Open(cfgPath)
+	data, err := os.ReadFile(cfgPath)
 	if err != nil {
 		return Config{}, fmt.Errorf("failed to open config file: %w", err)
 	}
-	defer func() {
-		_ = file.Close()
-	}()
+
+	if data, err = expandEnv(data); err != nil {
+		return Config{}, fmt.Errorf("failed to expand environment variables in config file: %w", err)
+	}
 
 	cfg := defaultConfig()
-	if _, err = toml.NewDecoder(file).Decode(&cfg); err != nil {
+	if _, err = decodeConfig(cfgPath, data, &cfg); err != nil {
 		return Config{}, fmt.Errorf("failed to decode config file: %w", err)
 	}
 
+	if err = applyEnvOverrides(&cfg, EnvPrefix); err != nil {
+		return Config{}, fmt.Errorf("failed to apply environment overrides: %w", err)
+	}
+
+	if cfg.HomeAssistant != nil && cfg.HomeAssistant.Token == "" && cfg.HomeAssistant.TokenFile != "" {
+		token, err := readSecretFile(cfg.HomeAssistant.TokenFile)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to read home assistant token file: %w", err)
+		}
+		cfg.HomeAssistant.Token = token
+	}
+
+	cfg.path = cfgPath
 	return cfg, nil
 }
 
+// readSecretFile reads a secret like a Docker secret from a file, surrounding whitespace is trimmed.
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// secretFields are config keys whose values are never logged.
+var secretFields = []string{"token", "password", "key", "authorization", "body"}
+
+// secretMapFields are config keys of maps whose values are never logged, e.g. HTTP headers like X-API-Key.
+var secretMapFields = []string{"headers"}
+
+// urlFields are config keys of URLs whose userinfo & query values are never logged.
+var urlFields = []string{"url", "broker"}
+
+// redactedField returns the redacted value of a secret struct field or false if the field is not secret.
+func redactedField(field reflect.StructField, v reflect.Value) (any, bool) {
+	name := strings.ToLower(field.Name)
+	switch {
+	case slices.Contains(secretFields, name):
+		return redact(v.String()), true
+	case slices.Contains(secretMapFields, name) && v.Kind() == reflect.Map:
+		m := make(map[string]string, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = redact(fmt.Sprint(iter.Value().Interface()))
+		}
+		return m, true
+	case slices.Contains(urlFields, name) && v.Kind() == reflect.String:
+		return redactURL(v.String()), true
+	}
+	return nil, false
+}
+
+// redactURL removes the userinfo and redacts the query values of a URL since they often contain credentials.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return redact(rawURL)
+	}
+	u.User = nil
+
+	if u.RawQuery != "" {
+		query := u.Query()
+		params := make([]string, 0, len(query))
+		for _, key := range slices.Sorted(maps.Keys(query)) {
+			params = append(params, url.QueryEscape(key)+"="+redact(query.Get(key)))
+		}
+		u.RawQuery = strings.Join(params, "&")
+	}
+	return u.String()
+}
+
+// configDiff returns the changed fields between the old and new config as "field: old -> new".
+// Values of secret fields are redacted.
+func configDiff(oldCfg Config, newCfg Config) []string {
+	return diffValues("", reflect.ValueOf(oldCfg), reflect.ValueOf(newCfg), nil)
+}
+
+func diffValues(path string, oldValue reflect.Value, newValue reflect.Value, changes []string) []string {
+	switch oldValue.Kind() {
+	case reflect.Pointer:
+		if oldValue.IsNil() || newValue.IsNil() {
+			if oldValue.IsNil() != newValue.IsNil() {
+				changes = append(changes, fmt.Sprintf("%s: %s -> %s", path, formatConfigured(oldValue), formatConfigured(newValue)))
+			}
+			return changes
+		}
+		return diffValues(path, oldValue.Elem(), newValue.Elem(), changes)
+	case reflect.Struct:
+		for i := range oldValue.NumField() {
+			field := oldValue.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+			if name == "" {
+				name = field.Name
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+
+			if _, ok := redactedField(field, oldValue.Field(i)); ok {
+				if !reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+					changes = append(changes, name+": changed")
+				}
+				continue
+			}
+			changes = diffValues(name, oldValue.Field(i), newValue.Field(i), changes)
+		}
+		return changes
+	}
+
+	if !reflect.DeepEqual(oldValue.Interface(), newValue.Interface()) {
+		changes = append(changes, fmt.Sprintf("%s: %v -> %v", path, oldValue.Interface(), newValue.Interface()))
+	}
+	return changes
+}
+
+// redacted replaces secrets in logs & responses.
+const redacted = "<redacted>"
+
+// redact hides a secret without revealing its length.
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
+
+// LogValue implements slog.LogValuer, secrets are redacted.
+func (c Config) LogValue() slog.Value {
+	return redactedLogValue(reflect.ValueOf(c))
+}
+
+func redactedLogValue(v reflect.Value) slog.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return slog.AnyValue(nil)
+		}
+		return redactedLogValue(v.Elem())
+	case reflect.Struct:
+		attrs := make([]slog.Attr, 0, v.NumField())
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+			if name == "" {
+				name = field.Name
+			}
+			if value, ok := redactedField(field, v.Field(i)); ok {
+				attrs = append(attrs, slog.Any(name, value))
+				continue
+			}
+			attrs = append(attrs, slog.Attr{Key: name, Value: redactedLogValue(v.Field(i))})
+		}
+		return slog.GroupValue(attrs...)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Struct {
+			break
+		}
+		attrs := make([]slog.Attr, 0, v.Len())
+		for i := range v.Len() {
+			attrs = append(attrs, slog.Attr{Key: strconv.Itoa(i), Value: redactedLogValue(v.Index(i))})
+		}
+		return slog.GroupValue(attrs...)
+	}
+	return slog.AnyValue(v.Interface())
+}
+
+// redactedValue converts a config into maps & slices keyed by the toml names for JSON responses, secrets are redacted.
+// Besides secret fields, map entries with a secret key like `token` in service data are redacted.
+func redactedValue(v reflect.Value) any {
+	if v.Type() == reflect.TypeFor[time.Duration]() {
+		return time.Duration(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redactedValue(v.Elem())
+	case reflect.Struct:
+		m := make(map[string]any, v.NumField())
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+			if name == "" {
+				name = field.Name
+			}
+			if value, ok := redactedField(field, v.Field(i)); ok {
+				m[name] = value
+				continue
+			}
+			m[name] = redactedValue(v.Field(i))
+		}
+		return m
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		s := make([]any, v.Len())
+		for i := range v.Len() {
+			s[i] = redactedValue(v.Index(i))
+		}
+		return s
+	case reflect.Map:
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			value := iter.Value()
+			if slices.Contains(secretFields, strings.ToLower(key)) {
+				m[key] = redact(fmt.Sprint(value.Interface()))
+				continue
+			}
+			m[key] = redactedValue(value)
+		}
+		return m
+	}
+	return v.Interface()
+}
+
+func formatConfigured(v reflect.Value) string {
+	if v.IsNil() {
+		return "not configured"
+	}
+	return "configured"
+}
+
 func defaultConfig() Config {
 	return Config{
 		Log: LogConfig{
@@ -41,24 +282,74 @@ func defaultConfig() Config {
 }
 
 type Config struct {
-	Dev           bool                 `toml:"dev"`
-	ListenAddr    string               `toml:"listen_addr"`
-	ListenPort    int                  `toml:"listen_port"`
-	DashboardDir  string               `toml:"dashboard_dir"`
-	Log           LogConfig            `toml:"log"`
-	HomeAssistant *HomeAssistantConfig `toml:"home_assistant"`
+	Dev            bool                 `toml:"dev"`
+	ListenAddr     string               `toml:"listen_addr"`
+	ListenPort     int                  `toml:"listen_port"`
+	DashboardDir   string               `toml:"dashboard_dir"`
+	StateFile      string               `toml:"state_file"`
+	TrustedProxies []string             `toml:"trusted_proxies"`
+	Log            LogConfig            `toml:"log"`
+	HomeAssistant  *HomeAssistantConfig `toml:"home_assistant"`
+	MQTT           *MQTTConfig          `toml:"mqtt"`
+	Auth           *AuthConfig          `toml:"auth"`
+	TLS            *TLSConfig           `toml:"tls"`
+
+	// path is the file the config was loaded from
+	path string
 }
 
 func (c Config) String() string {
-	return fmt.Sprintf("Dev: %t\nListenAddr: %s\nDashboardDir: %s\nLog: %s\nHomeAssistant: %v",
+	return fmt.Sprintf("Dev: %t\nListenAddr: %s\nDashboardDir: %s\nStateFile: %s\nTrustedProxies: %v\nLog: %s\nHomeAssistant: %v\nMQTT: %v\nAuth: %v\nTLS: %v",
 		c.Dev,
 		c.ListenAddr,
 		c.DashboardDir,
+		c.StateFile,
+		c.TrustedProxies,
 		c.Log,
 		c.HomeAssistant,
+		c.MQTT,
+		c.Auth,
+		c.TLS,
 	)
 }
 
+// isTrustedProxy returns whether the remote address of a request is one of the trusted proxies.
+// Trusted proxies are IP addresses or CIDR ranges, invalid entries are ignored.
+func (c Config) isTrustedProxy(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, proxy := range c.TrustedProxies {
+		prefix, err := parseTrustedProxy(proxy)
+		if err != nil {
+			continue
+		}
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTrustedProxy parses a trusted proxy IP address or CIDR range.
+func parseTrustedProxy(proxy string) (netip.Prefix, error) {
+	if strings.Contains(proxy, "/") {
+		return netip.ParsePrefix(proxy)
+	}
+	addr, err := netip.ParseAddr(proxy)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
 type LogFormat string
 
 const (
@@ -84,10 +375,11 @@ func (c LogConfig) String() string {
 }
 
 type HomeAssistantConfig struct {
-	Host   string `toml:"host"`
-	Port   int    `toml:"port"`
-	Secure bool   `toml:"secure"`
-	Token  string `toml:"token"`
+	Host      string `toml:"host"`
+	Port      int    `toml:"port"`
+	Secure    bool   `toml:"secure"`
+	Token     string `toml:"token"`
+	TokenFile string `toml:"token_file"`
 }
 
 func (c HomeAssistantConfig) URL() string {
@@ -100,10 +392,93 @@ func (c HomeAssistantConfig) URL() string {
 }
 
 func (c HomeAssistantConfig) String() string {
-	return fmt.Sprintf("\n Host: %s\n Port: %d\n Secure: %t\n Token: %s",
+	return fmt.Sprintf("\n Host: %s\n Port: %d\n Secure: %t\n Token: %s\n TokenFile: %s",
 		c.Host,
 		c.Port,
 		c.Secure,
-		strings.Repeat("*", len(c.Token)),
+		redact(c.Token),
+		c.TokenFile,
+	)
+}
+
+type MQTTConfig struct {
+	Broker   string            `toml:"broker"`
+	ClientID string            `toml:"client_id"`
+	Username string            `toml:"username"`
+	Password string            `toml:"password"`
+	Topics   []MQTTTopicConfig `toml:"topics"`
+}
+
+func (c MQTTConfig) String() string {
+	return fmt.Sprintf("\n Broker: %s\n ClientID: %s\n Username: %s\n Password: %s\n Topics: %v",
+		c.Broker,
+		c.ClientID,
+		c.Username,
+		redact(c.Password),
+		c.Topics,
+	)
+}
+
+type MQTTTopicConfig struct {
+	Name  string `toml:"name"`
+	Topic string `toml:"topic"`
+	QoS   byte   `toml:"qos"`
+}
+
+type TLSConfig struct {
+	CertFile   string   `toml:"cert_file"`
+	KeyFile    string   `toml:"key_file"`
+	SelfSigned bool     `toml:"self_signed"`
+	Hosts      []string `toml:"hosts"`
+	HTTPPort   int      `toml:"http_port"`
+}
+
+func (c TLSConfig) String() string {
+	return fmt.Sprintf("\n CertFile: %s\n KeyFile: %s\n SelfSigned: %t\n Hosts: %v\n HTTPPort: %d",
+		c.CertFile,
+		c.KeyFile,
+		c.SelfSigned,
+		c.Hosts,
+		c.HTTPPort,
+	)
+}
+
+type AuthConfig struct {
+	Keys  []APIKeyConfig   `toml:"keys"`
+	Users []AuthUserConfig `toml:"users"`
+}
+
+func (c AuthConfig) String() string {
+	return fmt.Sprintf("\n Keys: %v\n Users: %v",
+		c.Keys,
+		c.Users,
+	)
+}
+
+type APIKeyConfig struct {
+	Name       string   `toml:"name"`
+	Key        string   `toml:"key"`
+	Dashboards []string `toml:"dashboards"`
+}
+
+func (c APIKeyConfig) String() string {
+	return fmt.Sprintf("{Name: %s, Key: %s, Dashboards: %v}",
+		c.Name,
+		redact(c.Key),
+		c.Dashboards,
+	)
+}
+
+type AuthUserConfig struct {
+	Username   string   `toml:"username"`
+	Password   string   `toml:"password"`
+	Dashboards []string `toml:"dashboards"`
+}
+
+func (c AuthUserConfig) String() string {
+	return fmt.Sprintf("{Username: %s, Password: %s, Dashboards: %v}",
+		c.Username,
+		redact(c.Password),
+		c.Dashboards,
 	)
 }
@@ -0,0 +1,202 @@
+package dashboard
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	//go:embed status.gohtml
+	statusTemplateContent string
+	statusTemplate        = template.Must(template.New("status").Parse(statusTemplateContent))
+)
+
+// DeviceIDHeader can be used by devices which can set custom headers instead of the device query parameter.
+const DeviceIDHeader = "X-Device-ID"
+
+// DeviceStaleAfter is the duration after which a device which did not check in is considered offline.
+const DeviceStaleAfter = 15 * time.Minute
+
+type Device struct {
+	ID             string       `json:"id"`
+	Dashboard      string       `json:"dashboard"`
+	PageIndex      int          `json:"page_index"`
+	LastSeen       time.Time    `json:"last_seen"`
+	ETag           string       `json:"etag"`
+	Firmware       string       `json:"firmware,omitempty"`
+	IP             string       `json:"ip,omitempty"`
+	BatteryVoltage float64      `json:"battery_voltage,omitempty"`
+	RSSI           int          `json:"rssi,omitempty"`
+	Show           *ShowCommand `json:"show,omitempty"`
+}
+
+// ShowCommand forces a device to show a specific page until it expires.
+type ShowCommand struct {
+	PageIndex  int       `json:"page_index"`
+	Priority   int       `json:"priority"`
+	Until      time.Time `json:"until"`
+	ReturnPage int       `json:"return_page"`
+}
+
+func (c ShowCommand) IsActive() bool {
+	return time.Now().Before(c.Until)
+}
+
+// validFor returns whether the pages of the command exist in a dashboard with the given number of pages.
+func (c ShowCommand) validFor(pageCount int) bool {
+	return c.PageIndex >= 0 && c.PageIndex < pageCount && c.ReturnPage >= 0 && c.ReturnPage < pageCount
+}
+
+// IsStale returns whether the device did not check in for longer than DeviceStaleAfter.
+func (d Device) IsStale() bool {
+	return time.Since(d.LastSeen) > DeviceStaleAfter
+}
+
+// deviceID returns the id of the device sending the request or an empty string if the device did not identify itself.
+func deviceID(r *http.Request) string {
+	if id := r.URL.Query().Get("device"); id != "" {
+		return id
+	}
+	return strings.TrimSpace(r.Header.Get(DeviceIDHeader))
+}
+
+// trackDevice records the request of a device in the device store and applies fn to the device.
+// Optional device information like firmware, battery voltage & wifi rssi are read from the query parameters.
+func (s *Server) trackDevice(r *http.Request, id string, dashboard string, fn func(device *Device)) {
+	query := r.URL.Query()
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	// device ids are chosen by the clients, so they are not used as label to keep the number of series bounded
+	s.metrics.deviceRequests.Inc(dashboard, r.Pattern)
+
+	if _, err = s.devices.update(id, func(d *Device) {
+		d.Dashboard = dashboard
+		d.LastSeen = time.Now()
+		d.IP = ip
+		if firmware := query.Get("firmware"); firmware != "" {
+			d.Firmware = firmware
+		}
+		if battery, err := strconv.ParseFloat(query.Get("battery"), 64); err == nil {
+			d.BatteryVoltage = battery
+		}
+		if rssi, err := strconv.Atoi(query.Get("rssi")); err == nil {
+			d.RSSI = rssi
+		}
+		if fn != nil {
+			fn(d)
+		}
+	}); err != nil {
+		slog.ErrorContext(r.Context(), "failed to update device state", slog.String("device", id), slog.Any("err", err))
+	}
+}
+
+func newDeviceStore(path string) *deviceStore {
+	return &deviceStore{
+		path:    path,
+		devices: make(map[string]Device),
+	}
+}
+
+// deviceStore keeps track of all devices and persists them to disk if a path is configured.
+type deviceStore struct {
+	mu      sync.Mutex
+	path    string
+	devices map[string]Device
+}
+
+func (d *deviceStore) load() error {
+	if d.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(d.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read device state: %w", err)
+	}
+
+	devices := make(map[string]Device)
+	if err = json.Unmarshal(data, &devices); err != nil {
+		return fmt.Errorf("failed to decode device state: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.devices = devices
+	return nil
+}
+
+func (d *deviceStore) get(id string) (Device, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	device, ok := d.devices[id]
+	return device, ok
+}
+
+func (d *deviceStore) all() []Device {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return slices.SortedFunc(maps.Values(d.devices), func(a, b Device) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+}
+
+// update applies fn to the device with the given id (creating it if needed) and persists the new state.
+func (d *deviceStore) update(id string, fn func(device *Device)) (Device, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	device, ok := d.devices[id]
+	if !ok {
+		device = Device{
+			ID: id,
+		}
+	}
+	fn(&device)
+	d.devices[id] = device
+
+	return device, d.save()
+}
+
+func (d *deviceStore) save() error {
+	if d.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(d.devices, "", "\t")
+	if err != nil {
+		return fmt.Errorf("failed to encode device state: %w", err)
+	}
+
+	// write to a temporary file first so a crash never leaves a half written state file behind
+	tmpPath := filepath.Join(filepath.Dir(d.path), "."+filepath.Base(d.path)+".tmp")
+	if err = os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write device state: %w", err)
+	}
+	if err = os.Rename(tmpPath, d.path); err != nil {
+		return fmt.Errorf("failed to replace device state: %w", err)
+	}
+	return nil
+}
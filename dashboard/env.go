@@ -0,0 +1,150 @@
+package dashboard
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix is the prefix of environment variables overriding config values, e.g. ESPHOME_DASHBOARD_HOME_ASSISTANT_TOKEN for home_assistant.token.
+const EnvPrefix = "ESPHOME_DASHBOARD"
+
+// envVarPattern matches ${NAME}, ${NAME:-default} & the escaped form $${NAME}.
+var envVarPattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?}`)
+
+// expandEnv replaces ${NAME} with the value of the environment variable NAME.
+// ${NAME:-default} falls back to default if NAME is not set, $${NAME} is kept as ${NAME}.
+func expandEnv(data []byte) ([]byte, error) {
+	var errs []error
+	expanded := envVarPattern.ReplaceAllFunc(data, func(match []byte) []byte {
+		if match[1] == '$' {
+			return match[1:]
+		}
+
+		groups := envVarPattern.FindSubmatch(match)
+		name := string(groups[1])
+		if value, ok := os.LookupEnv(name); ok {
+			return []byte(value)
+		}
+		if strings.Contains(string(match), ":-") {
+			return groups[2]
+		}
+		errs = append(errs, fmt.Errorf("environment variable %s is not set", name))
+		return match
+	})
+
+	return expanded, errors.Join(errs...)
+}
+
+// applyEnvOverrides sets the fields of v from environment variables named prefix + "_" + the upper cased toml key path.
+// Only fields with scalar types, string slices (comma separated) & types implementing encoding.TextUnmarshaler are supported.
+// Nil struct pointers are allocated if any of their fields is overridden.
+func applyEnvOverrides(v any, prefix string) error {
+	_, err := applyEnvOverridesValue(reflect.ValueOf(v).Elem(), prefix)
+	return err
+}
+
+func applyEnvOverridesValue(v reflect.Value, prefix string) (bool, error) {
+	var set bool
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		envName := prefix + "_" + strings.ToUpper(name)
+		fieldValue := v.Field(i)
+
+		if field.Type.Kind() == reflect.Pointer && field.Type.Elem().Kind() == reflect.Struct {
+			elem := reflect.New(field.Type.Elem())
+			if !fieldValue.IsNil() {
+				elem = fieldValue
+			}
+			ok, err := applyEnvOverridesValue(elem.Elem(), envName)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				fieldValue.Set(elem)
+				set = true
+			}
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Struct && !reflect.PointerTo(field.Type).Implements(textUnmarshalerType) {
+			ok, err := applyEnvOverridesValue(fieldValue, envName)
+			if err != nil {
+				return false, err
+			}
+			set = set || ok
+			continue
+		}
+
+		value, ok := os.LookupEnv(envName)
+		if !ok {
+			continue
+		}
+		if err := setEnvValue(fieldValue, value); err != nil {
+			return false, fmt.Errorf("invalid value for %s: %w", envName, err)
+		}
+		set = true
+	}
+
+	return set, nil
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+func setEnvValue(v reflect.Value, value string) error {
+	if v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		var values []string
+		for _, s := range strings.Split(value, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				values = append(values, s)
+			}
+		}
+		v.Set(reflect.ValueOf(values).Convert(v.Type()))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
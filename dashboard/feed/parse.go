@@ -0,0 +1,204 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown feed format")
+
+	htmlTagRegex = regexp.MustCompile(`<[^>]*>`)
+	spaceRegex   = regexp.MustCompile(`\s+`)
+
+	dateLayouts = []string{
+		time.RFC1123Z,
+		time.RFC1123,
+		time.RFC3339,
+		time.RFC3339Nano,
+		time.RFC822Z,
+		time.RFC822,
+		"Mon, _2 Jan 2006 15:04:05 -0700",
+		"Mon, _2 Jan 2006 15:04:05 MST",
+		"_2 Jan 2006 15:04:05 -0700",
+		"2006-01-02T15:04:05",
+		time.DateOnly,
+	}
+)
+
+// Parse parses an RSS 2.0 or Atom feed.
+func Parse(r io.Reader) (*Feed, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %w", err)
+	}
+
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch root {
+	case "rss":
+		return parseRSS(data)
+	case "feed":
+		return parseAtom(data)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, root)
+	}
+}
+
+func rootElement(data []byte) (string, error) {
+	decoder := newDecoder(data)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("failed to find root element: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+func newDecoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	// non UTF-8 feeds are converted using the declared encoding, unknown encodings fail to decode
+	decoder.CharsetReader = charset.NewReaderLabel
+	return decoder
+}
+
+func parseRSS(data []byte) (*Feed, error) {
+	var doc rss
+	if err := newDecoder(data).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode rss feed: %w", err)
+	}
+
+	feed := &Feed{
+		Title: strings.TrimSpace(doc.Channel.Title),
+		Link:  strings.TrimSpace(doc.Channel.Link),
+		Items: make([]Item, 0, len(doc.Channel.Items)),
+	}
+	for _, item := range doc.Channel.Items {
+		date := item.PubDate
+		if date == "" {
+			date = item.Date
+		}
+
+		feed.Items = append(feed.Items, Item{
+			Title:     strings.TrimSpace(item.Title),
+			Link:      strings.TrimSpace(item.Link),
+			Published: parseDate(date),
+			Summary:   plainText(item.Description),
+			ImageURL:  rssImageURL(item),
+		})
+	}
+
+	return feed, nil
+}
+
+func rssImageURL(item rssItem) string {
+	for _, enclosure := range item.Enclosures {
+		if strings.HasPrefix(enclosure.Type, "image/") {
+			return enclosure.URL
+		}
+	}
+	for _, media := range item.Media {
+		if media.Medium == "image" || strings.HasPrefix(media.Type, "image/") {
+			return media.URL
+		}
+	}
+	for _, thumbnail := range item.Thumbnails {
+		if thumbnail.URL != "" {
+			return thumbnail.URL
+		}
+	}
+	return item.ITunesImage.Href
+}
+
+func parseAtom(data []byte) (*Feed, error) {
+	var doc atom
+	if err := newDecoder(data).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode atom feed: %w", err)
+	}
+
+	feed := &Feed{
+		Title: strings.TrimSpace(doc.Title),
+		Link:  atomLinkHref(doc.Links, "alternate"),
+		Items: make([]Item, 0, len(doc.Entries)),
+	}
+	for _, entry := range doc.Entries {
+		date := entry.Published
+		if date == "" {
+			date = entry.Updated
+		}
+
+		summary := entry.Summary
+		if summary == "" {
+			summary = entry.Content
+		}
+
+		imageURL := atomLinkHref(entry.Links, "enclosure")
+		if imageURL == "" && len(entry.Thumbnails) > 0 {
+			imageURL = entry.Thumbnails[0].URL
+		}
+
+		feed.Items = append(feed.Items, Item{
+			Title:     strings.TrimSpace(entry.Title),
+			Link:      atomLinkHref(entry.Links, "alternate"),
+			Published: parseDate(date),
+			Summary:   plainText(summary),
+			ImageURL:  imageURL,
+		})
+	}
+
+	return feed, nil
+}
+
+func atomLinkHref(links []atomLink, rel string) string {
+	for _, link := range links {
+		linkRel := link.Rel
+		if linkRel == "" {
+			linkRel = "alternate"
+		}
+		if linkRel != rel {
+			continue
+		}
+		if rel == "enclosure" && !strings.HasPrefix(link.Type, "image/") {
+			continue
+		}
+		return link.Href
+	}
+	return ""
+}
+
+func parseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// plainText strips all html tags from s and collapses whitespace.
+func plainText(s string) string {
+	s = htmlTagRegex.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	return strings.TrimSpace(spaceRegex.ReplaceAllString(s, " "))
+}
@@ -0,0 +1,134 @@
+package feed
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func parseFile(t *testing.T, name string) *Feed {
+	t.Helper()
+
+	file, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	f, err := Parse(file)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %s", name, err)
+	}
+	return f
+}
+
+func TestParseRSS(t *testing.T) {
+	f := parseFile(t, "rss.xml")
+
+	if f.Title != "tagesschau.de" || f.Link != "https://www.tagesschau.de" {
+		t.Errorf("unexpected feed: %q %q", f.Title, f.Link)
+	}
+	if len(f.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(f.Items))
+	}
+
+	item := f.Items[0]
+	if item.Title != "Bahnstreik beendet" || item.Link != "https://www.tagesschau.de/streik" {
+		t.Errorf("unexpected item: %q %q", item.Title, item.Link)
+	}
+	if item.Summary != "Die Züge rollen wieder & pünktlich." {
+		t.Errorf("unexpected summary: %q", item.Summary)
+	}
+	if expected := time.Date(2026, 5, 5, 12, 30, 0, 0, time.UTC); !item.Published.Equal(expected) {
+		t.Errorf("expected published %s, got %s", expected, item.Published)
+	}
+	if item.ImageURL != "https://www.tagesschau.de/streik.jpg" {
+		t.Errorf("unexpected image url: %q", item.ImageURL)
+	}
+
+	item = f.Items[1]
+	if item.Summary != "Sonne überall" {
+		t.Errorf("unexpected summary: %q", item.Summary)
+	}
+	if expected := time.Date(2026, 5, 5, 10, 0, 0, 0, time.UTC); !item.Published.Equal(expected) {
+		t.Errorf("expected published %s, got %s", expected, item.Published)
+	}
+	if item.ImageURL != "https://www.tagesschau.de/wetter.png" {
+		t.Errorf("unexpected image url: %q", item.ImageURL)
+	}
+}
+
+func TestParseAtom(t *testing.T) {
+	f := parseFile(t, "atom.xml")
+
+	if f.Title != "Release notes" || f.Link != "https://example.com/releases" {
+		t.Errorf("unexpected feed: %q %q", f.Title, f.Link)
+	}
+	if len(f.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(f.Items))
+	}
+
+	item := f.Items[0]
+	if item.Title != "v1.2.0" || item.Link != "https://example.com/releases/v1.2.0" {
+		t.Errorf("unexpected item: %q %q", item.Title, item.Link)
+	}
+	if item.Summary != "New features" {
+		t.Errorf("unexpected summary: %q", item.Summary)
+	}
+	if expected := time.Date(2026, 5, 4, 8, 0, 0, 0, time.UTC); !item.Published.Equal(expected) {
+		t.Errorf("expected published %s, got %s", expected, item.Published)
+	}
+	if item.ImageURL != "https://example.com/v1.2.0.png" {
+		t.Errorf("unexpected image url: %q", item.ImageURL)
+	}
+
+	item = f.Items[1]
+	if item.Link != "https://example.com/releases/v1.1.0" || item.Summary != "Bug fixes" {
+		t.Errorf("unexpected item: %q %q", item.Link, item.Summary)
+	}
+	if expected := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC); !item.Published.Equal(expected) {
+		t.Errorf("expected published %s, got %s", expected, item.Published)
+	}
+	if item.ImageURL != "https://example.com/v1.1.0.png" {
+		t.Errorf("unexpected image url: %q", item.ImageURL)
+	}
+}
+
+func TestParseCharset(t *testing.T) {
+	f := parseFile(t, "latin1.xml")
+	if len(f.Items) != 1 || f.Items[0].Title != "Grüße aus München" || f.Items[0].Summary != "Schönes Wetter" {
+		t.Errorf("unexpected items: %+v", f.Items)
+	}
+
+	f = parseFile(t, "windows1251.xml")
+	if f.Title != "Новости" {
+		t.Errorf("unexpected title: %q", f.Title)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		err    string
+		target error
+	}{
+		{name: "unsupported charset", data: `<?xml version="1.0" encoding="x-unknown"?><rss><channel><title>a</title></channel></rss>`, err: "x-unknown"},
+		{name: "unknown format", data: `<html><body>not a feed</body></html>`, err: "unknown feed format: html", target: ErrUnknownFormat},
+		{name: "empty", data: ``, err: "failed to find root element"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error containing %q, got %v", tt.err, err)
+			}
+			if tt.target != nil && !errors.Is(err, tt.target) {
+				t.Errorf("expected %v, got %v", tt.target, err)
+			}
+		})
+	}
+}
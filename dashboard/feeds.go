@@ -0,0 +1,74 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/topi314/esphome-dashboard/dashboard/feed"
+)
+
+func (s *Server) fetchFeeds(ctx context.Context, feeds []FeedConfig) map[string][]feed.Item {
+	items := make(map[string][]feed.Item)
+	for _, feedConfig := range feeds {
+		f, err := s.fetchFeed(ctx, feedConfig)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to fetch feed", slog.String("feed", feedConfig.Name), slog.String("url", feedConfig.URL), slog.Any("err", err))
+			continue
+		}
+
+		items[feedConfig.Name] = filterFeedItems(feedConfig, f.Items)
+	}
+
+	return items
+}
+
+func (s *Server) fetchFeed(ctx context.Context, feedConfig FeedConfig) (*feed.Feed, error) {
+	cacheKey := "feed:" + feedConfig.URL
+	if value, ok := s.cache.get(cacheKey); ok {
+		return value.(*feed.Feed), nil
+	}
+
+	rq, err := http.NewRequestWithContext(ctx, http.MethodGet, feedConfig.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	rq.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, text/xml;q=0.8")
+
+	rs, err := s.httpClient.Do(rq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer rs.Body.Close()
+
+	if rs.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", rs.Status)
+	}
+
+	f, err := feed.Parse(rs.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse feed: %w", err)
+	}
+
+	s.cache.set(cacheKey, f, feedConfig.CacheTTL)
+	return f, nil
+}
+
+func filterFeedItems(feedConfig FeedConfig, items []feed.Item) []feed.Item {
+	filtered := make([]feed.Item, 0, len(items))
+	for _, item := range items {
+		// items without a date can't be filtered by age, so we keep them
+		if feedConfig.MaxAge > 0 && !item.Published.IsZero() && time.Since(item.Published) > feedConfig.MaxAge {
+			continue
+		}
+		filtered = append(filtered, item)
+	}
+
+	if feedConfig.MaxItems > 0 && len(filtered) > feedConfig.MaxItems {
+		filtered = filtered[:feedConfig.MaxItems]
+	}
+
+	return filtered
+}
@@ -12,30 +12,44 @@ import (
 )
 
 func (s *Server) fetchHomeAssistantData(ctx context.Context, config DashboardHomeAssistantConfig) HomeAssistantRenderData {
-	entities, err := s.fetchHomeAssistantEntities(ctx, config.Entities)
+	// the client is loaded once since a config reload can replace or remove it while fetching
+	homeAssistant := s.homeAssistantClient()
+	if homeAssistant == nil {
+		if len(config.Entities) > 0 || len(config.Calendars) > 0 || len(config.Services) > 0 || len(config.Todos) > 0 {
+			slog.WarnContext(ctx, "home assistant not configured, skipping home assistant data")
+		}
+		return HomeAssistantRenderData{}
+	}
+
+	entities, err := s.fetchHomeAssistantEntities(ctx, homeAssistant, config.Entities)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to fetch home assistant entities", slog.Any("err", err))
 	}
-	calendars, err := s.fetchHomeAssistantCalendars(ctx, config.Calendars)
+	calendars, err := s.fetchHomeAssistantCalendars(ctx, homeAssistant, config.Calendars)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to fetch home assistant calendars", slog.Any("err", err))
 	}
-	services, err := s.fetchHomeAssistantServices(ctx, config.Services)
+	services, err := s.fetchHomeAssistantServices(ctx, homeAssistant, config.Services)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to fetch home assistant services", slog.Any("err", err))
 	}
+	todos, err := s.fetchHomeAssistantTodos(ctx, homeAssistant, config.Todos)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to fetch home assistant todos", slog.Any("err", err))
+	}
 
 	return HomeAssistantRenderData{
 		Entities:  entities,
 		Calendars: calendars,
 		Services:  services,
+		Todos:     todos,
 	}
 }
 
-func (s *Server) fetchHomeAssistantEntities(ctx context.Context, entities []EntityConfig) (map[string]homeassistant.EntityState, error) {
+func (s *Server) fetchHomeAssistantEntities(ctx context.Context, homeAssistant *homeassistant.Client, entities []EntityConfig) (map[string]homeassistant.EntityState, error) {
 	states := make(map[string]homeassistant.EntityState)
 	for _, entity := range entities {
-		state, err := s.homeAssistant.GetState(ctx, entity.ID)
+		state, err := homeAssistant.GetState(ctx, entity.ID)
 		if err != nil {
 			slog.ErrorContext(ctx, "failed to get entity state", slog.String("entity", entity.Name), slog.String("entity_id", entity.ID), slog.Any("err", err))
 			continue
@@ -46,7 +60,7 @@ func (s *Server) fetchHomeAssistantEntities(ctx context.Context, entities []Enti
 	return states, nil
 }
 
-func (s *Server) fetchHomeAssistantCalendars(ctx context.Context, calendars []CalendarConfig) (map[string][]CalendarDay, error) {
+func (s *Server) fetchHomeAssistantCalendars(ctx context.Context, homeAssistant *homeassistant.Client, calendars []CalendarConfig) (map[string][]CalendarDay, error) {
 	year, month, day := time.Now().Date()
 	start := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
 	weekStart := start.AddDate(0, 0, -weekdayToIndex(start.Weekday())) // move start at the beginning of the week
@@ -57,7 +71,7 @@ func (s *Server) fetchHomeAssistantCalendars(ctx context.Context, calendars []Ca
 
 		var allEvents []homeassistant.CalendarEvent
 		for i, id := range calendar.IDs {
-			events, err := s.homeAssistant.GetCalendar(ctx, id, weekStart, end)
+			events, err := homeAssistant.GetCalendar(ctx, id, weekStart, end)
 			if err != nil {
 				slog.ErrorContext(ctx, "failed to get calendar", slog.String("calendar", calendar.Name), slog.String("entity_id", id), slog.Any("err", err))
 				continue
@@ -180,7 +194,89 @@ func weekdayToIndex(weekday time.Weekday) int {
 	}
 }
 
-func (s *Server) fetchHomeAssistantServices(ctx context.Context, services []ServiceConfig) (map[string]homeassistant.Response, error) {
+func (s *Server) fetchHomeAssistantTodos(ctx context.Context, homeAssistant *homeassistant.Client, todos []TodoConfig) (map[string][]homeassistant.TodoItem, error) {
+	lists := make(map[string][]homeassistant.TodoItem)
+	for _, todo := range todos {
+		status := make([]homeassistant.TodoItemStatus, 0, len(todo.Status))
+		for _, st := range todo.Status {
+			status = append(status, homeassistant.TodoItemStatus(st))
+		}
+
+		var allItems []homeassistant.TodoItem
+		for _, id := range todo.IDs {
+			items, err := homeAssistant.GetTodoItems(ctx, id, status)
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to get todo items", slog.String("todo", todo.Name), slog.String("entity_id", id), slog.Any("err", err))
+				continue
+			}
+			allItems = append(allItems, items...)
+		}
+
+		lists[todo.Name] = filterAndSortTodoItems(todo, allItems, time.Now())
+	}
+
+	return lists, nil
+}
+
+// filterAndSortTodoItems filters the items by their due date relative to the day of now in its time zone.
+func filterAndSortTodoItems(todo TodoConfig, items []homeassistant.TodoItem, now time.Time) []homeassistant.TodoItem {
+	nowYear, nowMonth, nowDay := now.Date()
+	today := time.Date(nowYear, nowMonth, nowDay, 0, 0, 0, 0, now.Location())
+
+	filtered := make([]homeassistant.TodoItem, 0, len(items))
+	for _, item := range items {
+		if !item.HasDue() {
+			if todo.SkipWithoutDue {
+				continue
+			}
+			filtered = append(filtered, item)
+			continue
+		}
+
+		dueYear, dueMonth, dueDay := todoItemDue(item, now.Location()).Date()
+		due := time.Date(dueYear, dueMonth, dueDay, 0, 0, 0, 0, now.Location())
+		if todo.SkipOverdue && due.Before(today) {
+			continue
+		}
+		if todo.DueDays > 0 && !due.Before(today.AddDate(0, 0, todo.DueDays)) {
+			continue
+		}
+		filtered = append(filtered, item)
+	}
+
+	// items with a due date come first sorted by due date, items without keep their order
+	slices.SortStableFunc(filtered, func(a, b homeassistant.TodoItem) int {
+		switch {
+		case a.HasDue() && !b.HasDue():
+			return -1
+		case !a.HasDue() && b.HasDue():
+			return 1
+		case !a.HasDue() && !b.HasDue():
+			return 0
+		default:
+			return todoItemDue(a, now.Location()).Compare(todoItemDue(b, now.Location()))
+		}
+	})
+
+	if todo.MaxItems > 0 && len(filtered) > todo.MaxItems {
+		filtered = filtered[:todo.MaxItems]
+	}
+
+	return filtered
+}
+
+// todoItemDue returns the due time of the item in loc.
+// Due dates without a time are a day in any time zone, so they are returned as the start of that day in loc.
+func todoItemDue(item homeassistant.TodoItem, loc *time.Location) time.Time {
+	due := item.DueTime()
+	if item.HasDueTime() {
+		return due.In(loc)
+	}
+	year, month, day := due.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+func (s *Server) fetchHomeAssistantServices(ctx context.Context, homeAssistant *homeassistant.Client, services []ServiceConfig) (map[string]homeassistant.Response, error) {
 	responses := make(map[string]homeassistant.Response)
 	for _, service := range services {
 		data, err := json.Marshal(service.Data)
@@ -189,7 +285,7 @@ func (s *Server) fetchHomeAssistantServices(ctx context.Context, services []Serv
 			continue
 		}
 
-		response, err := s.homeAssistant.CallService(ctx, service.Domain, service.Service, bytes.NewReader(data), service.ReturnResponse)
+		response, err := homeAssistant.CallService(ctx, service.Domain, service.Service, bytes.NewReader(data), service.ReturnResponse)
 		if err != nil {
 			slog.ErrorContext(ctx, "failed to call service", slog.String("domain", service.Domain), slog.String("service", service.Service), slog.Any("err", err))
 			continue
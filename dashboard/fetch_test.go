@@ -0,0 +1,72 @@
+package dashboard
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/topi314/esphome-dashboard/dashboard/homeassistant"
+)
+
+func TestFilterAndSortTodoItems(t *testing.T) {
+	berlin := time.FixedZone("CEST", 2*60*60)
+	// shortly after midnight in Berlin, it's still the previous day in UTC
+	now := time.Date(2026, 5, 10, 0, 30, 0, 0, berlin)
+
+	items := []homeassistant.TodoItem{
+		{Summary: "no due"},
+		{Summary: "next week", Due: "2026-05-17"},
+		{Summary: "yesterday", Due: "2026-05-09"},
+		{Summary: "today", Due: "2026-05-10"},
+		{Summary: "today utc", Due: "2026-05-09T23:00:00Z"},
+		{Summary: "yesterday local", Due: "2026-05-09T23:30:00+02:00"},
+		{Summary: "tomorrow", Due: "2026-05-11T08:00:00+02:00"},
+		{Summary: "no due 2"},
+	}
+
+	tests := []struct {
+		name     string
+		todo     TodoConfig
+		expected []string
+	}{
+		{
+			name:     "sort by due",
+			todo:     TodoConfig{},
+			expected: []string{"yesterday", "yesterday local", "today", "today utc", "tomorrow", "next week", "no due", "no due 2"},
+		},
+		{
+			name:     "skip overdue",
+			todo:     TodoConfig{SkipOverdue: true},
+			expected: []string{"today", "today utc", "tomorrow", "next week", "no due", "no due 2"},
+		},
+		{
+			name:     "due days",
+			todo:     TodoConfig{DueDays: 1, SkipOverdue: true},
+			expected: []string{"today", "today utc", "no due", "no due 2"},
+		},
+		{
+			name:     "skip without due",
+			todo:     TodoConfig{DueDays: 7, SkipWithoutDue: true},
+			expected: []string{"yesterday", "yesterday local", "today", "today utc", "tomorrow"},
+		},
+		{
+			name:     "max items",
+			todo:     TodoConfig{SkipOverdue: true, MaxItems: 3},
+			expected: []string{"today", "today utc", "tomorrow"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := filterAndSortTodoItems(tt.todo, slices.Clone(items), now)
+
+			summaries := make([]string, 0, len(filtered))
+			for _, item := range filtered {
+				summaries = append(summaries, item.Summary)
+			}
+			if !slices.Equal(summaries, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, summaries)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package dashboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// configExtensions are the supported config file extensions in the order they are looked up.
+var configExtensions = []string{".toml", ".yaml", ".yml", ".json"}
+
+// FindConfigFile returns the config file with the given name and any supported extension in dir.
+// It returns an error if none or several of them exist.
+func FindConfigFile(dir string, name string) (string, error) {
+	var found []string
+	for _, ext := range configExtensions {
+		path := filepath.Join(dir, name+ext)
+		if _, err := os.Stat(path); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return "", fmt.Errorf("failed to stat config file: %w", err)
+		}
+		found = append(found, path)
+	}
+
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("no %s file found in %s: %w", name+"{"+strings.Join(configExtensions, ",")+"}", dir, fs.ErrNotExist)
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("found multiple config files, only one is allowed: %s", strings.Join(found, ", "))
+	}
+}
+
+// decodeConfig decodes a TOML, YAML or JSON config into v depending on the file extension of path.
+// YAML & JSON are converted to TOML first so all formats share the same decoding rules like durations as strings.
+func decodeConfig(path string, data []byte, v any) (toml.MetaData, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".toml":
+		return decodeTOML(string(data), v)
+	case ".yaml", ".yml", ".json":
+		var raw map[string]any
+		if ext == ".json" {
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.UseNumber()
+			if err := decoder.Decode(&raw); err != nil {
+				return toml.MetaData{}, err
+			}
+		} else if err := yaml.Unmarshal(data, &raw); err != nil {
+			return toml.MetaData{}, err
+		}
+
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(normalizeConfigValue(raw)); err != nil {
+			return toml.MetaData{}, fmt.Errorf("failed to convert config: %w", err)
+		}
+		return decodeTOML(buf.String(), v)
+	default:
+		return toml.MetaData{}, fmt.Errorf("unsupported config file extension %q, supported are %s", ext, strings.Join(configExtensions, ", "))
+	}
+}
+
+// normalizeConfigValue converts decoded YAML & JSON values into values the TOML encoder understands.
+// Null values are dropped since TOML has no equivalent.
+func normalizeConfigValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			if value == nil {
+				continue
+			}
+			m[key] = normalizeConfigValue(value)
+		}
+		return m
+	case []any:
+		s := make([]any, 0, len(v))
+		for _, value := range v {
+			if value == nil {
+				continue
+			}
+			s = append(s, normalizeConfigValue(value))
+		}
+		return s
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return v
+}
@@ -0,0 +1,47 @@
+package dashboard
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDecodeConfigDurations(t *testing.T) {
+	tests := []struct {
+		file string
+		data string
+		keys []string
+	}{
+		{file: "config.toml", data: "dwell_time = '5m'\n[[pages]]\npath = 'a.gohtml'\ndwell_time = '30s'\n"},
+		{file: "config.toml", data: "dwell_time = 300\n[[pages]]\npath = 'a.gohtml'\n[[pages]]\npath = 'b.gohtml'\ndwell_time = 30\n", keys: []string{"dwell_time", "pages.1.dwell_time"}},
+		{file: "config.yaml", data: "dwell_time: 5m\npages:\n  - path: a.gohtml\n    dwell_time: 30s\n"},
+		{file: "config.yaml", data: "refresh_interval: 60\npages:\n  - path: a.gohtml\n    dwell_time: 30\n", keys: []string{"pages.0.dwell_time", "refresh_interval"}},
+		{file: "config.json", data: `{"dwell_time": "5m", "pages": [{"path": "a.gohtml", "dwell_time": "30s"}]}`},
+		{file: "config.json", data: `{"home_assistant": {"todos": [{"name": "a", "due_days": 7}]}, "http_sources": [{"name": "a", "cache_ttl": 60}]}`, keys: []string{"http_sources.0.cache_ttl"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			var config DashboardConfig
+			_, err := decodeConfig(tt.file, []byte(tt.data), &config)
+			if len(tt.keys) == 0 {
+				if err != nil {
+					t.Fatalf("failed to decode config: %s", err)
+				}
+				if config.DwellTime != 5*time.Minute || config.Pages[0].DwellTime != 30*time.Second {
+					t.Errorf("unexpected durations: %s %s", config.DwellTime, config.Pages[0].DwellTime)
+				}
+				return
+			}
+
+			var durationErr *IntegerDurationError
+			if !errors.As(err, &durationErr) {
+				t.Fatalf("expected integer duration error, got %v", err)
+			}
+			if !slices.Equal(durationErr.Keys, tt.keys) {
+				t.Errorf("expected keys %v, got %v", tt.keys, durationErr.Keys)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"html/template"
+	"math"
 	"reflect"
 	"strings"
 	"time"
@@ -117,6 +118,41 @@ func formatTimeToRelDay(t time.Time) string {
 	}
 }
 
+// svgPoints scales values into a width x height box and returns them as SVG polyline points.
+func svgPoints(values []float64, width int, height int) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	minValue, maxValue := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		minValue = min(minValue, v)
+		maxValue = max(maxValue, v)
+	}
+
+	valueRange := maxValue - minValue
+	step := float64(width)
+	if len(values) > 1 {
+		step = float64(width) / float64(len(values)-1)
+	}
+
+	points := make([]string, 0, len(values))
+	for i, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		y := float64(height) / 2
+		if valueRange > 0 {
+			y = float64(height) - (v-minValue)/valueRange*float64(height)
+		}
+		points = append(points, fmt.Sprintf("%.1f,%.1f", float64(i)*step, y))
+	}
+	return strings.Join(points, " ")
+}
+
 func convertNewLinesToBR(a any) string {
 	return strings.ReplaceAll(fmt.Sprint(a), "\n", "<br>")
 }
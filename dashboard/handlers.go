@@ -1,14 +1,30 @@
 package dashboard
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
+	"mime"
 	"net/http"
+	"net/url"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
+)
+
+const (
+	// RefreshIntervalHeader contains the number of seconds after which the device should refresh the current page.
+	RefreshIntervalHeader = "X-Refresh-Interval"
+	// DwellTimeHeader contains the number of seconds the device should show the current page before moving to the next page.
+	DwellTimeHeader = "X-Dwell-Time"
 )
 
 type Action string
@@ -19,6 +35,7 @@ const (
 	ActionLastPage  Action = "last_page"
 	ActionPrevPage  Action = "prev_page"
 	ActionFirstPage Action = "first_page"
+	ActionGoto      Action = "goto"
 )
 
 func (s *Server) getVersion(w http.ResponseWriter, r *http.Request) {
@@ -29,44 +46,472 @@ func (s *Server) getVersion(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *Server) getDevices(w http.ResponseWriter, r *http.Request) {
+	slog.InfoContext(r.Context(), "getDevices")
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.devices.all()); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write response", slog.Any("err", err))
+	}
+}
+
+func (s *Server) getDashboards(w http.ResponseWriter, r *http.Request) {
+	slog.InfoContext(r.Context(), "getDashboards")
+
+	dashboards, err := s.ListDashboards()
+	if err != nil {
+		Error(r.Context(), w, fmt.Sprintf("failed to list dashboards: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(dashboards); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write response", slog.Any("err", err))
+	}
+}
+
+func (s *Server) getDashboard(w http.ResponseWriter, r *http.Request) {
+	dashboard := r.PathValue("dashboard")
+
+	slog.InfoContext(r.Context(), "getDashboard", slog.String("dashboard", dashboard))
+
+	dashboards, err := s.dashboards()
+	if err != nil {
+		Error(r.Context(), w, fmt.Sprintf("failed to list dashboards: %s", err), http.StatusInternalServerError)
+		return
+	}
+	if !slices.Contains(dashboards, dashboard) {
+		Error(r.Context(), w, "dashboard not found", http.StatusNotFound)
+		return
+	}
+
+	info, base := s.dashboardInfo(dashboard)
+	if base == nil {
+		Error(r.Context(), w, fmt.Sprintf("failed to load dashboard: %s", info.Error), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	if err = encoder.Encode(DashboardDetails{
+		DashboardInfo: info,
+		Config:        redactedValue(reflect.ValueOf(base.Config)),
+	}); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write response", slog.Any("err", err))
+	}
+}
+
+func (s *Server) getStatus(w http.ResponseWriter, r *http.Request) {
+	slog.InfoContext(r.Context(), "getStatus")
+
+	var buf bytes.Buffer
+	if err := statusTemplate.Execute(&buf, map[string]any{
+		"Version":   s.version,
+		"GoVersion": s.goVersion,
+		"Devices":   s.devices.all(),
+	}); err != nil {
+		Error(r.Context(), w, fmt.Sprintf("failed to render status page: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := io.Copy(w, &buf); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write response", slog.Any("err", err))
+	}
+}
+
+// ControlResponse is the response of the JSON variant of the control endpoint.
+type ControlResponse struct {
+	PageIndex       int       `json:"page_index"`
+	PageName        string    `json:"page_name"`
+	PageCount       int       `json:"page_count"`
+	ImageURL        string    `json:"image_url"`
+	ETag            string    `json:"etag,omitempty"`
+	RefreshInterval int       `json:"refresh_interval,omitempty"`
+	DwellTime       int       `json:"dwell_time,omitempty"`
+	RefreshAt       time.Time `json:"refresh_at"`
+	SleepDuration   int       `json:"sleep_duration"`
+}
+
 func (s *Server) getControl(w http.ResponseWriter, r *http.Request) {
+	s.control(w, r, acceptsJSON(r))
+}
+
+func (s *Server) getControlV2(w http.ResponseWriter, r *http.Request) {
+	s.control(w, r, true)
+}
+
+func (s *Server) control(w http.ResponseWriter, r *http.Request, jsonResponse bool) {
 	dashboard := r.PathValue("dashboard")
+	device := deviceID(r)
 
 	query := r.URL.Query()
 	action := Action(query.Get("action"))
 	lastPageStr := query.Get("page")
 
-	slog.InfoContext(r.Context(), "getControl", slog.String("dashboard", dashboard), slog.String("action", string(action)), slog.String("last_page", lastPageStr))
+	slog.InfoContext(r.Context(), "getControl", slog.String("dashboard", dashboard), slog.String("device", device), slog.String("action", string(action)), slog.String("target", query.Get("target")), slog.String("last_page", lastPageStr), slog.Bool("json", jsonResponse))
+
+	var (
+		lastPage   int
+		lastDevice Device
+	)
+	if device != "" {
+		if d, ok := s.devices.get(device); ok && d.Dashboard == dashboard {
+			lastDevice = d
+		}
+	}
+	if lastPageStr == "" && device == "" {
+		Error(r.Context(), w, "missing page number or device", http.StatusBadRequest)
+		return
+	}
 
-	lastPage, err := strconv.Atoi(lastPageStr)
+	config, err := s.getDashboardConfig(dashboard)
 	if err != nil {
-		Error(r.Context(), w, "invalid page number", http.StatusBadRequest)
+		Error(r.Context(), w, fmt.Sprintf("failed to get dashboard config: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	if lastPageStr != "" {
+		if lastPage, err = strconv.Atoi(lastPageStr); err != nil {
+			if lastPage, err = s.findPageIndex(dashboard, config, lastPageStr); err != nil {
+				Error(r.Context(), w, fmt.Sprintf("invalid page: %s", err), http.StatusBadRequest)
+				return
+			}
+		}
+	} else {
+		// devices which identified themselves don't need to remember their page
+		lastPage = lastDevice.PageIndex
+	}
+
+	// the rotation rules may query Home Assistant, so they are only evaluated once per request
+	active := s.activePages(r.Context(), config.Pages, time.Now())
+
+	// show commands are honoured for the automatic actions as long as no page with a higher priority is active
+	var showing bool
+	show := lastDevice.Show
+	if show != nil {
+		switch {
+		case !show.validFor(len(config.Pages)):
+			// the pages of the command were removed from the config since it was stored
+			slog.WarnContext(r.Context(), "dropping show command for removed page", slog.String("dashboard", dashboard), slog.String("device", device), slog.Int("page", show.PageIndex), slog.Int("return_page", show.ReturnPage))
+			show = nil
+		case !show.IsActive():
+			// the command expired, continue the rotation where the device left off
+			lastPage = show.ReturnPage
+			show = nil
+		case action == ActionRefresh || action == ActionNextPage:
+			showing = show.Priority >= highestActivePriority(config.Pages, active)
+		default:
+			// explicit navigation cancels the command
+			show = nil
+		}
+	}
+
+	var pageIndex int
+	if showing {
+		pageIndex = show.PageIndex
+	} else {
+		pageIndex, err = s.getNextPageIndex(dashboard, config, active, lastPage, action, query.Get("target"))
+		if err != nil {
+			Error(r.Context(), w, fmt.Sprintf("failed to get next page index: %s", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if device != "" {
+		s.trackDevice(r, device, dashboard, func(d *Device) {
+			d.PageIndex = pageIndex
+			d.Show = show
+		})
+	}
+
+	timing := s.getPageTiming(dashboard, config, pageIndex)
+	if timing.RefreshInterval > 0 {
+		w.Header().Set(RefreshIntervalHeader, strconv.Itoa(int(timing.RefreshInterval.Seconds())))
+	}
+	if timing.DwellTime > 0 {
+		w.Header().Set(DwellTimeHeader, strconv.Itoa(int(timing.DwellTime.Seconds())))
+	}
+
+	if !jsonResponse {
+		if _, err = w.Write([]byte(fmt.Sprintf("%d", pageIndex))); err != nil {
+			Error(r.Context(), w, "failed to write response", http.StatusInternalServerError)
+		}
 		return
 	}
 
-	pageIndex, err := s.getNextPageIndex(dashboard, lastPage, action)
+	format := query.Get("format")
+	if format == "" {
+		format = "png"
+	}
+	imageQuery := url.Values{"format": {format}}
+	if device != "" {
+		imageQuery.Set("device", device)
+	}
+	// clients authenticating via the query can't set headers for the image request either
+	if token := query.Get("token"); token != "" {
+		imageQuery.Set("token", token)
+	}
+
+	var etag string
+	if lastDevice.PageIndex == pageIndex {
+		etag = lastDevice.ETag
+	}
+
+	now := time.Now()
+	sleep := capSleepAtShow(s.getSleep(r.Context(), dashboard, config, pageIndex, now), show, now)
+
+	name := pageName(config.Pages[pageIndex].Path)
+	if pages, err := s.dashboardPages(dashboard); err == nil && pageIndex < len(pages) {
+		name = pages[pageIndex].Name
+	}
+
+	response := ControlResponse{
+		PageIndex:       pageIndex,
+		PageName:        name,
+		PageCount:       len(config.Pages),
+		ImageURL:        fmt.Sprintf("%s/dashboards/%s/pages/%d?%s", s.baseURL(r), url.PathEscape(dashboard), pageIndex, imageQuery.Encode()),
+		ETag:            etag,
+		RefreshInterval: int(timing.RefreshInterval.Seconds()),
+		DwellTime:       int(timing.DwellTime.Seconds()),
+		RefreshAt:       sleep.WakeAt,
+		SleepDuration:   int(sleep.Duration.Seconds()),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	if err = encoder.Encode(response); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write response", slog.Any("err", err))
+	}
+}
+
+// ShowRequest is the request body of the show endpoint.
+// Page can be a page index or name, Duration a duration string like `2m` or a number of seconds.
+type ShowRequest struct {
+	Page     any `json:"page"`
+	Duration any `json:"duration"`
+	Priority int `json:"priority"`
+}
+
+func (s *Server) postShow(w http.ResponseWriter, r *http.Request) {
+	dashboard := r.PathValue("dashboard")
+	device := r.PathValue("device")
+
+	var rq ShowRequest
+	if err := json.NewDecoder(r.Body).Decode(&rq); err != nil {
+		Error(r.Context(), w, fmt.Sprintf("failed to decode request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "postShow", slog.String("dashboard", dashboard), slog.String("device", device), slog.Any("page", rq.Page), slog.Any("duration", rq.Duration), slog.Int("priority", rq.Priority))
+
+	config, err := s.getDashboardConfig(dashboard)
 	if err != nil {
-		Error(r.Context(), w, fmt.Sprintf("failed to get next page index: %s", err), http.StatusInternalServerError)
+		Error(r.Context(), w, fmt.Sprintf("failed to get dashboard config: %s", err), http.StatusInternalServerError)
 		return
 	}
 
-	if _, err = w.Write([]byte(fmt.Sprintf("%d", pageIndex))); err != nil {
-		Error(r.Context(), w, "failed to write response", http.StatusInternalServerError)
+	var page string
+	switch p := rq.Page.(type) {
+	case string:
+		page = p
+	case float64:
+		page = strconv.Itoa(int(p))
+	default:
+		Error(r.Context(), w, "page must be a page index or name", http.StatusBadRequest)
+		return
+	}
+
+	pageIndex, err := s.findPageIndex(dashboard, config, page)
+	if err != nil {
+		Error(r.Context(), w, fmt.Sprintf("invalid page: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	var duration time.Duration
+	switch d := rq.Duration.(type) {
+	case string:
+		duration, err = time.ParseDuration(d)
+		if err != nil {
+			Error(r.Context(), w, fmt.Sprintf("invalid duration: %s", err), http.StatusBadRequest)
+			return
+		}
+	case float64:
+		duration = time.Duration(d * float64(time.Second))
+	}
+	if duration <= 0 {
+		Error(r.Context(), w, "duration must be positive", http.StatusBadRequest)
+		return
+	}
+
+	var otherDashboard bool
+	updated, err := s.devices.update(device, func(d *Device) {
+		// show commands can't move devices of other dashboards since API keys can be scoped to a dashboard
+		if d.Dashboard != "" && d.Dashboard != dashboard {
+			otherDashboard = true
+			return
+		}
+
+		returnPage := d.PageIndex
+		if d.Dashboard == "" {
+			returnPage = 0
+		} else if d.Show != nil && d.Show.IsActive() && d.Show.validFor(len(config.Pages)) {
+			// keep returning to the page before the first command
+			returnPage = d.Show.ReturnPage
+		}
+
+		d.Dashboard = dashboard
+		d.Show = &ShowCommand{
+			PageIndex:  pageIndex,
+			Priority:   rq.Priority,
+			Until:      time.Now().Add(duration),
+			ReturnPage: returnPage,
+		}
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to update device state", slog.String("device", device), slog.Any("err", err))
+	}
+	if otherDashboard {
+		Error(r.Context(), w, "unknown device", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(updated.Show); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write response", slog.Any("err", err))
 	}
 }
 
+func (s *Server) deleteShow(w http.ResponseWriter, r *http.Request) {
+	dashboard := r.PathValue("dashboard")
+	device := r.PathValue("device")
+
+	slog.InfoContext(r.Context(), "deleteShow", slog.String("dashboard", dashboard), slog.String("device", device))
+
+	d, ok := s.devices.get(device)
+	if !ok || d.Dashboard != dashboard {
+		Error(r.Context(), w, "unknown device", http.StatusNotFound)
+		return
+	}
+
+	if _, err := s.devices.update(device, func(d *Device) {
+		if d.Show != nil {
+			// shorten the command so the device returns to its previous page on the next poll
+			d.Show.Until = time.Now()
+		}
+	}); err != nil {
+		slog.ErrorContext(r.Context(), "failed to update device state", slog.String("device", device), slog.Any("err", err))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SleepResponse is the JSON response of the sleep endpoint.
+type SleepResponse struct {
+	SleepDuration int         `json:"sleep_duration"`
+	WakeAt        time.Time   `json:"wake_at"`
+	Reason        SleepReason `json:"reason"`
+}
+
+func (s *Server) getSleepDuration(w http.ResponseWriter, r *http.Request) {
+	dashboard := r.PathValue("dashboard")
+	device := deviceID(r)
+	pageStr := r.URL.Query().Get("page")
+
+	slog.InfoContext(r.Context(), "getSleepDuration", slog.String("dashboard", dashboard), slog.String("device", device), slog.String("page", pageStr))
+
+	config, err := s.getDashboardConfig(dashboard)
+	if err != nil {
+		Error(r.Context(), w, fmt.Sprintf("failed to get dashboard config: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	var lastDevice Device
+	if device != "" {
+		if d, ok := s.devices.get(device); ok && d.Dashboard == dashboard {
+			lastDevice = d
+		}
+	}
+
+	pageIndex := lastDevice.PageIndex
+	if pageStr != "" {
+		pageIndex, err = s.findPageIndex(dashboard, config, pageStr)
+		if err != nil {
+			Error(r.Context(), w, fmt.Sprintf("invalid page: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// a device showing a page because of a show command has to wake up when the command expires
+	now := time.Now()
+	sleep := capSleepAtShow(s.getSleep(r.Context(), dashboard, config, pageIndex, now), lastDevice.Show, now)
+
+	if device != "" {
+		s.trackDevice(r, device, dashboard, nil)
+	}
+
+	if !acceptsJSON(r) {
+		if _, err = w.Write([]byte(strconv.Itoa(int(sleep.Duration.Seconds())))); err != nil {
+			Error(r.Context(), w, "failed to write response", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err = json.NewEncoder(w).Encode(SleepResponse{
+		SleepDuration: int(sleep.Duration.Seconds()),
+		WakeAt:        sleep.WakeAt,
+		Reason:        sleep.Reason,
+	}); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write response", slog.Any("err", err))
+	}
+}
+
+// acceptsJSON returns whether the client prefers a JSON response.
+func acceptsJSON(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := mime.ParseMediaType(strings.TrimSpace(accept))
+		if mediaType == "application/json" {
+			return true
+		}
+	}
+	return false
+}
+
+// baseURL returns the scheme and host the request was sent to.
+// The X-Forwarded-Proto header is only honoured for requests from trusted proxies.
+func (s *Server) baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := strings.ToLower(r.Header.Get("X-Forwarded-Proto")); (proto == "http" || proto == "https") && s.config().isTrustedProxy(r.RemoteAddr) {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
+
 func (s *Server) getPage(w http.ResponseWriter, r *http.Request) {
 	dashboard := r.PathValue("dashboard")
 	pageIndexStr := r.PathValue("page")
+	device := deviceID(r)
 
 	query := r.URL.Query()
 	format := query.Get("format")
 
-	slog.InfoContext(r.Context(), "getPage", slog.String("dashboard", dashboard), slog.String("page", pageIndexStr), slog.String("format", format))
+	slog.InfoContext(r.Context(), "getPage", slog.String("dashboard", dashboard), slog.String("device", device), slog.String("page", pageIndexStr), slog.String("format", format))
+
+	config, err := s.getDashboardConfig(dashboard)
+	if err != nil {
+		Error(r.Context(), w, fmt.Sprintf("failed to get dashboard config: %s", err), http.StatusInternalServerError)
+		return
+	}
 
-	pageIndex, err := strconv.Atoi(pageIndexStr)
+	pageIndex, err := s.findPageIndex(dashboard, config, pageIndexStr)
 	if err != nil {
-		Error(r.Context(), w, "invalid page number", http.StatusBadRequest)
+		Error(r.Context(), w, fmt.Sprintf("invalid page: %s", err), http.StatusBadRequest)
 		return
 	}
 
@@ -94,11 +539,6 @@ func (s *Server) getPage(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	}
-	config, err := s.getDashboardConfig(dashboard)
-	if err != nil {
-		Error(r.Context(), w, fmt.Sprintf("failed to get dashboard config: %s", err), http.StatusInternalServerError)
-		return
-	}
 
 	content, contentLength, contentType, err := s.renderDashboard(r.Context(), dashboard, pageIndex, config.Width, config.Height, format)
 	if err != nil {
@@ -106,20 +546,47 @@ func (s *Server) getPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	data := make([]byte, 0, contentLength)
+	buf := bytes.NewBuffer(data)
+	if _, err = io.Copy(buf, content); err != nil {
+		Error(r.Context(), w, fmt.Sprintf("failed to read rendered page: %s", err), http.StatusInternalServerError)
+		return
+	}
+	etag := contentETag(buf.Bytes())
+
+	if device != "" {
+		s.trackDevice(r, device, dashboard, func(d *Device) {
+			d.PageIndex = pageIndex
+			d.ETag = etag
+		})
+	}
+
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	w.Header().Set("Content-Type", contentType)
-	w.Header().Set("Content-Length", strconv.Itoa(contentLength))
-	if _, err = io.Copy(w, content); err != nil {
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	if _, err = io.Copy(w, buf); err != nil {
 		slog.ErrorContext(r.Context(), "failed to write response", slog.Any("err", err))
 	}
 }
 
+// contentETag returns a strong ETag for the given content.
+func contentETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
 func (s *Server) getAsset(w http.ResponseWriter, r *http.Request) {
 	dashboard := r.PathValue("dashboard")
 	path := strings.TrimPrefix(r.URL.Path, "/dashboards/"+dashboard+"/assets")
 
 	slog.InfoContext(r.Context(), "getAssets", slog.String("dashboard", dashboard), slog.String("path", path))
 
-	http.ServeFile(w, r, filepath.Join(s.cfg.DashboardDir, dashboard, "assets", path))
+	http.ServeFile(w, r, filepath.Join(s.config().DashboardDir, dashboard, "assets", path))
 }
 
 func Error(ctx context.Context, w http.ResponseWriter, error string, code int) {
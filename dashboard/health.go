@@ -0,0 +1,141 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// readinessTimeout is the maximum duration of a single readiness check.
+const readinessTimeout = 5 * time.Second
+
+type CheckStatus string
+
+const (
+	CheckStatusOK    CheckStatus = "ok"
+	CheckStatusError CheckStatus = "error"
+)
+
+type CheckResult struct {
+	Status   CheckStatus `json:"status"`
+	Duration string      `json:"duration"`
+	Error    string      `json:"error,omitempty"`
+}
+
+type ReadinessResponse struct {
+	Status CheckStatus            `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+func (s *Server) getHealthz(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"status":"ok"}`))
+}
+
+func (s *Server) getReadyz(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]func(ctx context.Context) error{
+		"chrome":            s.checkChrome,
+		"dashboard_dir":     s.checkDashboardDir,
+		"dashboard_configs": s.checkDashboardConfigs,
+	}
+	if homeAssistant := s.homeAssistantClient(); homeAssistant != nil {
+		checks["home_assistant"] = func(ctx context.Context) error {
+			_, err := homeAssistant.Test(ctx)
+			return err
+		}
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	response := ReadinessResponse{
+		Status: CheckStatusOK,
+		Checks: make(map[string]CheckResult, len(checks)),
+	}
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check(ctx)
+			result := CheckResult{
+				Status:   CheckStatusOK,
+				Duration: time.Since(start).String(),
+			}
+			if err != nil {
+				slog.ErrorContext(r.Context(), "readiness check failed", slog.String("check", name), slog.Any("err", err))
+				result.Status = CheckStatusError
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			response.Checks[name] = result
+			if err != nil {
+				response.Status = CheckStatusError
+			}
+		}()
+	}
+	wg.Wait()
+
+	w.Header().Set("Content-Type", "application/json")
+	if response.Status != CheckStatusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write response", slog.Any("err", err))
+	}
+}
+
+// checkChrome opens a new tab to make sure the headless chrome is still responsive.
+func (s *Server) checkChrome(ctx context.Context) error {
+	if chromedp.FromContext(ctx) == nil {
+		return errors.New("chrome not started")
+	}
+
+	tabCtx, cancel := chromedp.NewContext(ctx)
+	defer cancel()
+
+	if err := chromedp.Run(tabCtx, chromedp.Navigate("about:blank")); err != nil {
+		return fmt.Errorf("chrome not responsive: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) checkDashboardDir(_ context.Context) error {
+	if _, err := os.ReadDir(s.config().DashboardDir); err != nil {
+		return fmt.Errorf("failed to read dashboard dir: %w", err)
+	}
+	return nil
+}
+
+// checkDashboardConfigs checks that all dashboards can be loaded.
+// Dashboards whose current files are broken fail the check even while their last good version is served.
+func (s *Server) checkDashboardConfigs(_ context.Context) error {
+	dashboards, err := s.dashboards()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, dashboard := range dashboards {
+		if _, err = s.getDashboardConfig(dashboard); err != nil {
+			errs = append(errs, fmt.Errorf("dashboard %s: %w", dashboard, err))
+		} else if err = s.dashboardReloadError(dashboard); err != nil {
+			errs = append(errs, fmt.Errorf("dashboard %s: %w", dashboard, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -9,6 +9,7 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 )
 
@@ -26,6 +27,34 @@ type Client struct {
 	url    string
 	token  string
 	client *http.Client
+	hook   RequestHook
+}
+
+// Close closes all idle connections to Home Assistant.
+func (c *Client) Close() {
+	c.client.CloseIdleConnections()
+}
+
+// RequestHook is called after every request to Home Assistant, err is also set for non 2xx responses.
+type RequestHook func(endpoint string, entityID string, duration time.Duration, err error)
+
+// OnRequest sets the hook which is called after every request, e.g. to record metrics.
+func (c *Client) OnRequest(hook RequestHook) {
+	c.hook = hook
+}
+
+// requestEndpoint splits the request path into the API endpoint and the entity it targets.
+func requestEndpoint(path string) (string, string) {
+	path = strings.Trim(strings.TrimPrefix(path, "/api"), "/")
+	endpoint, entityID, _ := strings.Cut(path, "/")
+	switch endpoint {
+	case "":
+		return "api", ""
+	case "services":
+		// services are addressed by domain & service, entities are passed in the body
+		return "services/" + entityID, ""
+	}
+	return endpoint, entityID
 }
 
 func (c *Client) Do(rq *http.Request) (*http.Response, error) {
@@ -38,7 +67,16 @@ func (c *Client) Do(rq *http.Request) (*http.Response, error) {
 	}
 	slog.DebugContext(rq.Context(), "Sending request to Home Assistant", slog.String("method", rq.Method), slog.String("url", rq.URL.String()), slog.String("body", string(body)))
 	rq.Header.Set("Authorization", "Bearer "+c.token)
+	start := time.Now()
 	rs, err := c.client.Do(rq)
+	if c.hook != nil {
+		hookErr := err
+		if err == nil && (rs.StatusCode < 200 || rs.StatusCode >= 300) {
+			hookErr = fmt.Errorf("unexpected status: %s", rs.Status)
+		}
+		endpoint, entityID := requestEndpoint(rq.URL.Path)
+		c.hook(endpoint, entityID, time.Since(start), hookErr)
+	}
 	if err != nil {
 		return rs, err
 	}
@@ -65,6 +103,10 @@ func (c *Client) Test(ctx context.Context) (string, error) {
 	}
 	defer rs.Body.Close()
 
+	if rs.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to test connection: unexpected status: %s", rs.Status)
+	}
+
 	var status Status
 	if err = json.NewDecoder(rs.Body).Decode(&status); err != nil {
 		return "", fmt.Errorf("failed to decode test response: %w", err)
@@ -162,3 +204,41 @@ func (c *Client) CallService(ctx context.Context, domain string, service string,
 
 	return response, nil
 }
+
+func (c *Client) GetTodoItems(ctx context.Context, entityID string, status []TodoItemStatus) ([]TodoItem, error) {
+	data := map[string]any{
+		"entity_id": entityID,
+	}
+	if len(status) > 0 {
+		data["status"] = status
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal todo items request: %w", err)
+	}
+
+	rq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/api/services/todo/get_items?return_response", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create todo items request: %w", err)
+	}
+
+	rq.Header.Set("Content-Type", "application/json")
+
+	rs, err := c.Do(rq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get todo items: %w", err)
+	}
+	defer rs.Body.Close()
+
+	if rs.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get todo items: %s", rs.Status)
+	}
+
+	var response TodoResponse
+	if err = json.NewDecoder(rs.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode todo items: %w", err)
+	}
+
+	return response.ServiceResponse[entityID].Items, nil
+}
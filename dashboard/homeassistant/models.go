@@ -77,3 +77,59 @@ type Response struct {
 	ChangedStates   []EntityState  `json:"changed_states"`
 	ServiceResponse map[string]any `json:"service_response"`
 }
+
+type TodoItemStatus string
+
+const (
+	TodoItemStatusNeedsAction TodoItemStatus = "needs_action"
+	TodoItemStatusCompleted   TodoItemStatus = "completed"
+)
+
+type TodoItem struct {
+	UID         string         `json:"uid"`
+	Summary     string         `json:"summary"`
+	Status      TodoItemStatus `json:"status"`
+	Description string         `json:"description"`
+	Due         string         `json:"due"`
+}
+
+// HasDue returns whether the item has a due date or due date-time.
+func (i TodoItem) HasDue() bool {
+	return i.Due != ""
+}
+
+// HasDueTime returns whether the item is due at a specific time and not just on a day.
+func (i TodoItem) HasDueTime() bool {
+	return len(i.Due) > len(time.DateOnly)
+}
+
+// DueTime returns the parsed due date of the item or a zero time if the item has no due date.
+func (i TodoItem) DueTime() time.Time {
+	if i.Due == "" {
+		return time.Time{}
+	}
+	if !i.HasDueTime() {
+		date, err := time.Parse(time.DateOnly, i.Due)
+		if err != nil {
+			return time.Time{}
+		}
+		return date
+	}
+	dateTime, err := time.Parse(time.RFC3339, i.Due)
+	if err != nil {
+		return time.Time{}
+	}
+	return dateTime
+}
+
+func (i TodoItem) IsCompleted() bool {
+	return i.Status == TodoItemStatusCompleted
+}
+
+type TodoList struct {
+	Items []TodoItem `json:"items"`
+}
+
+type TodoResponse struct {
+	ServiceResponse map[string]TodoList `json:"service_response"`
+}
@@ -0,0 +1,55 @@
+package dashboard
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/topi314/esphome-dashboard/dashboard/metrics"
+)
+
+func newServerMetrics() *serverMetrics {
+	registry := metrics.NewRegistry()
+	m := &serverMetrics{
+		registry:             registry,
+		executeDuration:      registry.NewHistogram("dashboard_execute_duration_seconds", "Duration of fetching data & executing the templates of a page.", metrics.DefaultBuckets, "dashboard"),
+		renderDuration:       registry.NewHistogram("dashboard_render_duration_seconds", "Duration of the stages of rendering a page to an image.", metrics.DefaultBuckets, "dashboard", "stage"),
+		renderErrors:         registry.NewCounter("dashboard_render_errors_total", "Number of failed renders of a page to an image.", "dashboard"),
+		homeAssistantLatency: registry.NewHistogram("dashboard_home_assistant_request_duration_seconds", "Duration of requests to Home Assistant.", metrics.DefaultBuckets, "endpoint", "entity"),
+		homeAssistantErrors:  registry.NewCounter("dashboard_home_assistant_request_errors_total", "Number of failed requests to Home Assistant.", "endpoint", "entity"),
+		cacheRequests:        registry.NewCounter("dashboard_cache_requests_total", "Number of data source cache lookups by result (hit or miss).", "kind", "result"),
+		chromeTabs:           registry.NewGauge("dashboard_chrome_tabs", "Number of currently open Chrome tabs."),
+		deviceRequests:       registry.NewCounter("dashboard_device_requests_total", "Number of requests of identified devices by dashboard & route.", "dashboard", "route"),
+		dashboardReloads:     registry.NewCounter("dashboard_reloads_total", "Number of dashboard loads after file changes by result (success or error).", "dashboard", "result"),
+	}
+	m.chromeTabs.Set(0)
+	return m
+}
+
+type serverMetrics struct {
+	registry             *metrics.Registry
+	executeDuration      *metrics.HistogramVec
+	renderDuration       *metrics.HistogramVec
+	renderErrors         *metrics.CounterVec
+	homeAssistantLatency *metrics.HistogramVec
+	homeAssistantErrors  *metrics.CounterVec
+	cacheRequests        *metrics.CounterVec
+	chromeTabs           *metrics.GaugeVec
+	deviceRequests       *metrics.CounterVec
+	dashboardReloads     *metrics.CounterVec
+}
+
+// observeHomeAssistantRequest implements homeassistant.RequestHook.
+func (m *serverMetrics) observeHomeAssistantRequest(endpoint string, entityID string, duration time.Duration, err error) {
+	m.homeAssistantLatency.Observe(duration.Seconds(), endpoint, entityID)
+	if err != nil {
+		m.homeAssistantErrors.Inc(endpoint, entityID)
+	}
+}
+
+func (s *Server) getMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := s.metrics.registry.WriteTo(w); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write response", slog.Any("err", err))
+	}
+}
@@ -0,0 +1,179 @@
+package metrics
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func writeRegistry(t *testing.T, r *Registry) string {
+	t.Helper()
+
+	var buf strings.Builder
+	n, err := r.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("failed to write metrics: %s", err)
+	}
+	if int(n) != buf.Len() {
+		t.Errorf("expected %d written bytes, got %d", buf.Len(), n)
+	}
+	return buf.String()
+}
+
+func TestCounter(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("requests_total", "Number of requests.", "route", "result")
+	c.Inc("/b", "ok")
+	c.Inc("/a", "ok")
+	c.Add(2.5, "/a", "ok")
+	c.Inc("/a", "error")
+
+	expected := `# HELP requests_total Number of requests.
+# TYPE requests_total counter
+requests_total{route="/a",result="error"} 1
+requests_total{route="/a",result="ok"} 3.5
+requests_total{route="/b",result="ok"} 1
+`
+	if got := writeRegistry(t, r); got != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestCounterPanics(t *testing.T) {
+	c := NewRegistry().NewCounter("requests_total", "Number of requests.", "route")
+
+	for name, fn := range map[string]func(){
+		"negative value":  func() { c.Add(-1, "/a") },
+		"missing label":   func() { c.Inc() },
+		"too many labels": func() { c.Inc("/a", "b") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			fn()
+		})
+	}
+}
+
+func TestGauge(t *testing.T) {
+	r := NewRegistry()
+	g := r.NewGauge("chrome_tabs", "Number of open tabs.")
+	g.Set(3)
+	g.Inc()
+	g.Dec()
+	g.Dec()
+
+	expected := `# HELP chrome_tabs Number of open tabs.
+# TYPE chrome_tabs gauge
+chrome_tabs 2
+`
+	if got := writeRegistry(t, r); got != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	r := NewRegistry()
+	// buckets are sorted on registration
+	h := r.NewHistogram("render_duration_seconds", "Duration of renders.", []float64{1, 0.1, 0.5}, "dashboard")
+	h.Observe(0.05, "default")
+	h.Observe(0.1, "default")
+	h.Observe(0.7, "default")
+	h.Observe(3, "default")
+	h.Observe(0.2, "kitchen")
+
+	expected := `# HELP render_duration_seconds Duration of renders.
+# TYPE render_duration_seconds histogram
+render_duration_seconds_bucket{dashboard="default",le="0.1"} 2
+render_duration_seconds_bucket{dashboard="default",le="0.5"} 2
+render_duration_seconds_bucket{dashboard="default",le="1"} 3
+render_duration_seconds_bucket{dashboard="default",le="+Inf"} 4
+render_duration_seconds_sum{dashboard="default"} 3.85
+render_duration_seconds_count{dashboard="default"} 4
+render_duration_seconds_bucket{dashboard="kitchen",le="0.1"} 0
+render_duration_seconds_bucket{dashboard="kitchen",le="0.5"} 1
+render_duration_seconds_bucket{dashboard="kitchen",le="1"} 1
+render_duration_seconds_bucket{dashboard="kitchen",le="+Inf"} 1
+render_duration_seconds_sum{dashboard="kitchen"} 0.2
+render_duration_seconds_count{dashboard="kitchen"} 1
+`
+	if got := writeRegistry(t, r); got != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestHistogramWithoutLabels(t *testing.T) {
+	r := NewRegistry()
+	h := r.NewHistogram("latency_seconds", "Latency.", []float64{1})
+	h.Observe(2)
+
+	expected := `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="1"} 0
+latency_seconds_bucket{le="+Inf"} 1
+latency_seconds_sum 2
+latency_seconds_count 1
+`
+	if got := writeRegistry(t, r); got != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestEscaping(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounter("device_requests_total", "Requests by device.\nBackslashes \\ are escaped.", "device")
+	c.Inc(`kitchen "frame"`)
+	c.Inc("C:\\frame\nnew line")
+
+	expected := `# HELP device_requests_total Requests by device.\nBackslashes \\ are escaped.
+# TYPE device_requests_total counter
+device_requests_total{device="C:\\frame\nnew line"} 1
+device_requests_total{device="kitchen \"frame\""} 1
+`
+	if got := writeRegistry(t, r); got != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestRegistryOrder(t *testing.T) {
+	r := NewRegistry()
+	r.NewGauge("b", "B.").Set(1)
+	r.NewCounter("a", "A.").Inc()
+	// metrics without samples only write their header
+	r.NewCounter("c", "C.", "label")
+
+	expected := `# HELP b B.
+# TYPE b gauge
+b 1
+# HELP a A.
+# TYPE a counter
+a 1
+# HELP c C.
+# TYPE c counter
+`
+	if got := writeRegistry(t, r); got != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestFormatFloat(t *testing.T) {
+	for value, expected := range map[float64]string{
+		0:            "0",
+		1:            "1",
+		0.005:        "0.005",
+		1e21:         "1e+21",
+		-2.5:         "-2.5",
+		math.Inf(1):  "+Inf",
+		math.Inf(-1): "-Inf",
+	} {
+		if got := formatFloat(value); got != expected {
+			t.Errorf("formatFloat(%v): expected %s, got %s", value, expected, got)
+		}
+	}
+	if got := formatFloat(math.NaN()); got != "NaN" {
+		t.Errorf("formatFloat(NaN): expected NaN, got %s", got)
+	}
+}
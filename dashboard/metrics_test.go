@@ -0,0 +1,63 @@
+package dashboard
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetMetrics(t *testing.T) {
+	s := New(defaultConfig(), "test", "go", nil)
+	s.metrics.dashboardReloads.Inc("kitchen", "success")
+	s.metrics.executeDuration.Observe(0.2, "kitchen")
+
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "text/plain; version=0.0.4; charset=utf-8" {
+		t.Errorf("unexpected content type: %s", contentType)
+	}
+
+	body, _ := io.ReadAll(w.Body)
+	for _, line := range []string{
+		"# TYPE dashboard_chrome_tabs gauge",
+		"dashboard_chrome_tabs 0",
+		"# TYPE dashboard_reloads_total counter",
+		`dashboard_reloads_total{dashboard="kitchen",result="success"} 1`,
+		"# TYPE dashboard_execute_duration_seconds histogram",
+		`dashboard_execute_duration_seconds_bucket{dashboard="kitchen",le="0.25"} 1`,
+		`dashboard_execute_duration_seconds_bucket{dashboard="kitchen",le="+Inf"} 1`,
+		`dashboard_execute_duration_seconds_count{dashboard="kitchen"} 1`,
+	} {
+		if !strings.Contains(string(body), line+"\n") {
+			t.Errorf("expected line %q in:\n%s", line, body)
+		}
+	}
+}
+
+func TestGetMetricsRequiresAuth(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Auth = &AuthConfig{
+		Keys: []APIKeyConfig{{Name: "prometheus", Key: "secret"}},
+	}
+	s := New(cfg, "test", "go", nil)
+
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status 401 without key, got %d", w.Code)
+	}
+
+	rq := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rq.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, rq)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200 with key, got %d", w.Code)
+	}
+}
@@ -0,0 +1,114 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+)
+
+func New(broker string, clientID string, username string, password string, topics []Topic) *Client {
+	c := &Client{
+		topics:   topics,
+		messages: make(map[string]Message),
+	}
+
+	opts := paho.NewClientOptions().
+		AddBroker(broker).
+		SetClientID(clientID).
+		SetUsername(username).
+		SetPassword(password).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetOnConnectHandler(c.onConnect).
+		SetConnectionLostHandler(func(_ paho.Client, err error) {
+			slog.Error("lost connection to mqtt broker", slog.Any("err", err))
+		})
+
+	c.client = paho.NewClient(opts)
+	return c
+}
+
+type Client struct {
+	client   paho.Client
+	topics   []Topic
+	mu       sync.RWMutex
+	messages map[string]Message
+}
+
+// Connect connects to the broker. Subscriptions are (re)created on every successful connection.
+func (c *Client) Connect(ctx context.Context) error {
+	token := c.client.Connect()
+	select {
+	case <-token.Done():
+		if err := token.Error(); err != nil {
+			return fmt.Errorf("failed to connect to mqtt broker: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Client) Close() {
+	c.client.Disconnect(250)
+}
+
+// Messages returns a snapshot of the last received message of each configured topic by name.
+func (c *Client) Messages() map[string]Message {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return maps.Clone(c.messages)
+}
+
+func (c *Client) onConnect(client paho.Client) {
+	slog.Info("connected to mqtt broker")
+	for _, topic := range c.topics {
+		token := client.Subscribe(topic.Topic, topic.QoS, c.onMessage(topic))
+		go func() {
+			<-token.Done()
+			if err := token.Error(); err != nil {
+				slog.Error("failed to subscribe to mqtt topic", slog.String("name", topic.Name), slog.String("topic", topic.Topic), slog.Any("err", err))
+			}
+		}()
+	}
+}
+
+func (c *Client) onMessage(topic Topic) paho.MessageHandler {
+	wildcard := strings.ContainsAny(topic.Topic, "+#")
+	return func(_ paho.Client, msg paho.Message) {
+		message := Message{
+			Topic:      msg.Topic(),
+			Payload:    string(msg.Payload()),
+			Retained:   msg.Retained(),
+			ReceivedAt: time.Now(),
+		}
+
+		var data any
+		if err := json.Unmarshal(msg.Payload(), &data); err == nil {
+			message.JSON = data
+		}
+
+		slog.Debug("Received mqtt message", slog.String("name", topic.Name), slog.String("topic", message.Topic), slog.String("payload", message.Payload))
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if wildcard {
+			// the map is copied since snapshots returned by Messages share it
+			topics := maps.Clone(c.messages[topic.Name].Topics)
+			if topics == nil {
+				topics = make(map[string]Message)
+			}
+			topics[message.Topic] = message
+			message.Topics = topics
+		}
+		c.messages[topic.Name] = message
+	}
+}
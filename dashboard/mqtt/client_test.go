@@ -0,0 +1,172 @@
+package mqtt
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	mqttserver "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+)
+
+// startBroker starts an in-process broker listening on a random local port and returns its address.
+func startBroker(t *testing.T) (*mqttserver.Server, string) {
+	t.Helper()
+
+	broker := mqttserver.New(&mqttserver.Options{
+		InlineClient: true,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err := broker.AddHook(new(auth.AllowHook), nil); err != nil {
+		t.Fatalf("failed to add auth hook: %s", err)
+	}
+
+	listener := listeners.NewTCP(listeners.Config{
+		ID:      "tcp",
+		Address: "127.0.0.1:0",
+	})
+	if err := broker.AddListener(listener); err != nil {
+		t.Fatalf("failed to add listener: %s", err)
+	}
+	if err := broker.Serve(); err != nil {
+		t.Fatalf("failed to start broker: %s", err)
+	}
+	t.Cleanup(func() {
+		_ = broker.Close()
+	})
+
+	return broker, "tcp://" + listener.Address()
+}
+
+// connect connects a client subscribed to the given topics to the broker.
+func connect(t *testing.T, addr string, topics []Topic) *Client {
+	t.Helper()
+
+	client := New(addr, "test", "", "", topics)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("failed to connect: %s", err)
+	}
+	t.Cleanup(client.Close)
+
+	return client
+}
+
+// waitForMessage waits until the client received a message for the given name which matches the check.
+func waitForMessage(t *testing.T, client *Client, name string, check func(Message) bool) Message {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if message, ok := client.Messages()[name]; ok && check(message) {
+			return message
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for message %q, got: %+v", name, client.Messages()[name])
+	return Message{}
+}
+
+func TestClientRetainedMessage(t *testing.T) {
+	broker, addr := startBroker(t)
+
+	// the message is published before the client subscribes, so it is only received because it is retained
+	if err := broker.Publish("zigbee2mqtt/living_room", []byte(`{"temperature":21.5,"humidity":48}`), true, 0); err != nil {
+		t.Fatalf("failed to publish: %s", err)
+	}
+
+	client := connect(t, addr, []Topic{{Name: "LivingRoom", Topic: "zigbee2mqtt/living_room"}})
+
+	message := waitForMessage(t, client, "LivingRoom", func(Message) bool { return true })
+	if !message.Retained {
+		t.Error("expected message to be retained")
+	}
+	if message.Topic != "zigbee2mqtt/living_room" {
+		t.Errorf("unexpected topic: %s", message.Topic)
+	}
+	if message.Payload != `{"temperature":21.5,"humidity":48}` {
+		t.Errorf("unexpected payload: %s", message.Payload)
+	}
+
+	data, ok := message.JSON.(map[string]any)
+	if !ok {
+		t.Fatalf("expected JSON object, got %T", message.JSON)
+	}
+	if data["temperature"] != 21.5 {
+		t.Errorf("unexpected temperature: %v", data["temperature"])
+	}
+}
+
+func TestClientLiveMessages(t *testing.T) {
+	broker, addr := startBroker(t)
+
+	client := connect(t, addr, []Topic{
+		{Name: "Power", Topic: "tasmota/+/POWER"},
+		{Name: "Counter", Topic: "pihole/counter", QoS: 1},
+	})
+
+	// wait for the subscriptions to be active before publishing
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if err := broker.Publish("tasmota/plug/POWER", []byte("ON"), false, 0); err != nil {
+			t.Fatalf("failed to publish: %s", err)
+		}
+		if _, ok := client.Messages()["Power"]; ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for subscription")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// payloads which aren't JSON are only available raw
+	message := waitForMessage(t, client, "Power", func(Message) bool { return true })
+	if message.Retained {
+		t.Error("expected live message not to be retained")
+	}
+	if message.Topic != "tasmota/plug/POWER" {
+		t.Errorf("unexpected topic: %s", message.Topic)
+	}
+	if message.Payload != "ON" {
+		t.Errorf("unexpected payload: %s", message.Payload)
+	}
+	if message.JSON != nil {
+		t.Errorf("expected no JSON value, got %v", message.JSON)
+	}
+
+	// messages of wildcard subscriptions are kept per matching topic
+	if err := broker.Publish("tasmota/lamp/POWER", []byte("OFF"), false, 0); err != nil {
+		t.Fatalf("failed to publish: %s", err)
+	}
+	message = waitForMessage(t, client, "Power", func(m Message) bool { return m.Topic == "tasmota/lamp/POWER" })
+	if message.Payload != "OFF" {
+		t.Errorf("unexpected payload: %s", message.Payload)
+	}
+	if len(message.Topics) != 2 || message.Topics["tasmota/plug/POWER"].Payload != "ON" || message.Topics["tasmota/lamp/POWER"].Payload != "OFF" {
+		t.Errorf("unexpected topics: %+v", message.Topics)
+	}
+
+	// the last message per topic is kept
+	if err := broker.Publish("pihole/counter", []byte("41"), false, 1); err != nil {
+		t.Fatalf("failed to publish: %s", err)
+	}
+	if err := broker.Publish("pihole/counter", []byte("42"), false, 1); err != nil {
+		t.Fatalf("failed to publish: %s", err)
+	}
+	message = waitForMessage(t, client, "Counter", func(m Message) bool { return m.Payload == "42" })
+	if message.JSON != float64(42) {
+		t.Errorf("unexpected JSON value: %v", message.JSON)
+	}
+	if message.Topics != nil {
+		t.Errorf("expected no topics without wildcards, got %+v", message.Topics)
+	}
+
+	if _, ok := client.Messages()["Unknown"]; ok {
+		t.Error("expected no message for unknown topic")
+	}
+}
@@ -0,0 +1,21 @@
+package mqtt
+
+import (
+	"time"
+)
+
+type Topic struct {
+	Name  string
+	Topic string
+	QoS   byte
+}
+
+type Message struct {
+	Topic      string
+	Payload    string
+	JSON       any
+	Retained   bool
+	ReceivedAt time.Time
+	// Topics are the last messages of each matching topic by topic if the subscription contains wildcards
+	Topics map[string]Message
+}
@@ -1,26 +1,91 @@
 package dashboard
 
 import (
+	"errors"
 	"fmt"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/adrg/frontmatter"
 )
 
 type DashboardConfig struct {
-	Height        int                          `toml:"height"`
-	Width         int                          `toml:"width"`
-	Base          string                       `toml:"base"`
-	Pages         []string                     `toml:"pages"`
-	HomeAssistant DashboardHomeAssistantConfig `toml:"home_assistant"`
+	Height          int                          `toml:"height"`
+	Width           int                          `toml:"width"`
+	Base            string                       `toml:"base"`
+	RefreshInterval time.Duration                `toml:"refresh_interval"`
+	DwellTime       time.Duration                `toml:"dwell_time"`
+	QuietHours      *ScheduleConfig              `toml:"quiet_hours"`
+	Pages           []PageConfig                 `toml:"pages"`
+	HomeAssistant   DashboardHomeAssistantConfig `toml:"home_assistant"`
+	HTTPSources     []HTTPSourceConfig           `toml:"http_sources"`
+	Feeds           []FeedConfig                 `toml:"feeds"`
+	Prometheus      *PrometheusConfig            `toml:"prometheus"`
+}
+
+type PageConfig struct {
+	Path            string            `toml:"path"`
+	Name            string            `toml:"name"`
+	Aliases         []string          `toml:"aliases"`
+	RefreshInterval time.Duration     `toml:"refresh_interval"`
+	DwellTime       time.Duration     `toml:"dwell_time"`
+	Schedules       []ScheduleConfig  `toml:"schedules"`
+	Conditions      []ConditionConfig `toml:"conditions"`
+	Priority        int               `toml:"priority"`
+}
+
+// UnmarshalTOML allows pages to be configured as a plain path or as a table with rotation rules.
+func (c *PageConfig) UnmarshalTOML(data any) error {
+	switch v := data.(type) {
+	case string:
+		*c = PageConfig{Path: v}
+		return nil
+	case map[string]any:
+		// re-encode the table so we can decode it using the struct tags without recursing into this method
+		type pageConfig PageConfig
+		raw, err := toml.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to encode page config: %w", err)
+		}
+
+		var cfg pageConfig
+		if err = toml.Unmarshal(raw, &cfg); err != nil {
+			return fmt.Errorf("failed to decode page config: %w", err)
+		}
+		*c = PageConfig(cfg)
+		return nil
+	default:
+		return fmt.Errorf("invalid page config type: %T", data)
+	}
+}
+
+type ScheduleConfig struct {
+	From     string   `toml:"from"`
+	To       string   `toml:"to"`
+	Weekdays []string `toml:"weekdays"`
+}
+
+type ConditionConfig struct {
+	Entity    string   `toml:"entity"`
+	States    []string `toml:"states"`
+	NotStates []string `toml:"not_states"`
 }
 
 type DashboardHomeAssistantConfig struct {
 	Entities  []EntityConfig   `toml:"entities"`
 	Calendars []CalendarConfig `toml:"calendars"`
 	Services  []ServiceConfig  `toml:"services"`
+	Todos     []TodoConfig     `toml:"todos"`
 }
 
 type EntityConfig struct {
@@ -46,80 +111,413 @@ type ServiceConfig struct {
 	Data           map[string]any `toml:"data"`
 }
 
+type TodoConfig struct {
+	Name           string   `toml:"name"`
+	IDs            []string `toml:"ids"`
+	Status         []string `toml:"status"`
+	DueDays        int      `toml:"due_days"`
+	SkipOverdue    bool     `toml:"skip_overdue"`
+	SkipWithoutDue bool     `toml:"skip_without_due"`
+	MaxItems       int      `toml:"max_items"`
+}
+
+type HTTPSourceConfig struct {
+	Name     string            `toml:"name"`
+	URL      string            `toml:"url"`
+	Method   string            `toml:"method"`
+	Headers  map[string]string `toml:"headers"`
+	Body     string            `toml:"body"`
+	Extract  string            `toml:"extract"`
+	CacheTTL time.Duration     `toml:"cache_ttl"`
+}
+
+type FeedConfig struct {
+	Name     string        `toml:"name"`
+	URL      string        `toml:"url"`
+	MaxItems int           `toml:"max_items"`
+	MaxAge   time.Duration `toml:"max_age"`
+	CacheTTL time.Duration `toml:"cache_ttl"`
+}
+
+type PrometheusConfig struct {
+	URL     string                  `toml:"url"`
+	Token   string                  `toml:"token"`
+	Queries []PrometheusQueryConfig `toml:"queries"`
+}
+
+type PrometheusQueryConfig struct {
+	Name     string        `toml:"name"`
+	Query    string        `toml:"query"`
+	Range    time.Duration `toml:"range"`
+	Step     time.Duration `toml:"step"`
+	CacheTTL time.Duration `toml:"cache_ttl"`
+}
+
+// getDashboardConfig returns the config of the currently loaded version of a dashboard.
+// Dashboards which are not in the registry only have their config read from disk.
 func (s *Server) getDashboardConfig(dashboard string) (*DashboardConfig, error) {
-	configFile, err := os.Open(filepath.Join(s.cfg.DashboardDir, dashboard, "config.toml"))
+	entry, ok := s.registryEntry(dashboard)
+	if !ok {
+		return s.readDashboardConfig(dashboard)
+	}
+	if entry.base == nil {
+		return nil, entry.err
+	}
+
+	config := entry.base.Config
+	return &config, nil
+}
+
+// readDashboardConfig reads & decodes the config of a dashboard from disk.
+func (s *Server) readDashboardConfig(dashboard string) (*DashboardConfig, error) {
+	cfgPath, err := s.dashboardConfigFile(dashboard)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(cfgPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open config file: %w", err)
 	}
-	defer configFile.Close()
+
+	if data, err = expandEnv(data); err != nil {
+		return nil, fmt.Errorf("failed to expand environment variables in config: %w", err)
+	}
 
 	var config DashboardConfig
-	if _, err = toml.NewDecoder(configFile).Decode(&config); err != nil {
+	if _, err = decodeConfig(cfgPath, data, &config); err != nil {
 		return nil, fmt.Errorf("failed to decode config: %w", err)
 	}
 
 	return &config, nil
 }
 
-func (s *Server) getNextPageIndex(dashboard string, lastPage int, action Action) (int, error) {
-	config, err := s.getDashboardConfig(dashboard)
+// decodeTOML decodes a TOML config into v.
+// Durations must be strings, bare integers are rejected since they would be decoded as nanoseconds.
+func decodeTOML(data string, v any) (toml.MetaData, error) {
+	var raw map[string]any
+	if _, err := toml.Decode(data, &raw); err != nil {
+		return toml.MetaData{}, err
+	}
+	if err := checkDurations(raw, reflect.TypeOf(v)); err != nil {
+		return toml.MetaData{}, err
+	}
+	return toml.Decode(data, v)
+}
+
+// IntegerDurationError is returned when durations are configured as integers instead of strings like "10m".
+type IntegerDurationError struct {
+	// Keys are the dotted key paths of the durations, e.g. "pages.1.dwell_time"
+	Keys []string
+	// Values are the configured integers of the keys
+	Values []int64
+}
+
+func (e *IntegerDurationError) Error() string {
+	return fmt.Sprintf("durations must be strings like \"10m\", found integers for %s", strings.Join(e.Keys, ", "))
+}
+
+// checkDurations returns an *IntegerDurationError if the decoded config contains integers for duration fields of t.
+func checkDurations(raw any, t reflect.Type) error {
+	var durationErr IntegerDurationError
+	findIntegerDurations(raw, t, "", &durationErr)
+	if len(durationErr.Keys) > 0 {
+		return &durationErr
+	}
+	return nil
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+func findIntegerDurations(raw any, t reflect.Type, key string, durationErr *IntegerDurationError) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == durationType {
+		if i, ok := raw.(int64); ok {
+			durationErr.Keys = append(durationErr.Keys, key)
+			durationErr.Values = append(durationErr.Values, i)
+		}
+		return
+	}
+
+	join := func(name string) string {
+		if key == "" {
+			return name
+		}
+		return key + "." + name
+	}
+
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		m, ok := raw.(map[string]any)
+		if !ok {
+			return
+		}
+		for _, name := range slices.Sorted(maps.Keys(m)) {
+			value := m[name]
+			if t.Kind() == reflect.Map {
+				findIntegerDurations(value, t.Elem(), join(name), durationErr)
+			} else if field, ok := tomlField(t, name); ok {
+				findIntegerDurations(value, field.Type, join(name), durationErr)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		// arrays of tables are decoded as []map[string]any, other arrays as []any
+		v := reflect.ValueOf(raw)
+		if v.Kind() != reflect.Slice {
+			return
+		}
+		for i := range v.Len() {
+			findIntegerDurations(v.Index(i).Interface(), t.Elem(), join(strconv.Itoa(i)), durationErr)
+		}
+	}
+}
+
+// dashboardConfigFile returns the path of the TOML, YAML or JSON config of a dashboard.
+func (s *Server) dashboardConfigFile(dashboard string) (string, error) {
+	return FindConfigFile(filepath.Join(s.config().DashboardDir, dashboard), "config")
+}
+
+// dashboards returns the names of all directories in the dashboard dir containing a dashboard config.
+func (s *Server) dashboards() ([]string, error) {
+	dashboardDir := s.config().DashboardDir
+	entries, err := os.ReadDir(dashboardDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dashboard dir: %w", err)
+	}
+
+	var dashboards []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		// dashboards with several configs are included so the error is reported when they are used
+		if _, err = FindConfigFile(filepath.Join(dashboardDir, entry.Name()), "config"); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		dashboards = append(dashboards, entry.Name())
+	}
+	return dashboards, nil
+}
+
+type DashboardInfo struct {
+	Name   string     `json:"name"`
+	Title  string     `json:"title,omitempty"`
+	Width  int        `json:"width"`
+	Height int        `json:"height"`
+	Pages  []PageInfo `json:"pages"`
+	Error  string     `json:"error,omitempty"`
+}
+
+type PageInfo struct {
+	Index   int      `json:"index"`
+	Name    string   `json:"name"`
+	Title   string   `json:"title,omitempty"`
+	Aliases []string `json:"aliases"`
+	Path    string   `json:"path"`
+}
+
+type DashboardDetails struct {
+	DashboardInfo
+	// Config is the resolved dashboard config keyed by the config names, secrets are redacted
+	Config any `json:"config"`
+}
+
+// ListDashboards returns all dashboards with their pages.
+// Dashboards which can't be loaded are included with the error.
+func (s *Server) ListDashboards() ([]DashboardInfo, error) {
+	dashboards, err := s.dashboards()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]DashboardInfo, 0, len(dashboards))
+	for _, dashboard := range dashboards {
+		info, _ := s.dashboardInfo(dashboard)
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// dashboardInfo returns the info of a dashboard and its currently loaded version, which is nil if it can't be loaded.
+func (s *Server) dashboardInfo(dashboard string) (DashboardInfo, *Base) {
+	info := DashboardInfo{
+		Name:  dashboard,
+		Pages: []PageInfo{},
+	}
+	base, err := s.loadedDashboard(dashboard)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get dashboard config: %w", err)
+		info.Error = err.Error()
+		return info, nil
+	}
+	// the last good version is listed while the current files fail to load
+	if err = s.dashboardReloadError(dashboard); err != nil {
+		info.Error = err.Error()
+	}
+
+	info.Title, _ = base.Vars["title"].(string)
+	info.Width = base.Config.Width
+	info.Height = base.Config.Height
+	for _, page := range base.Pages {
+		title, _ := page.Vars["title"].(string)
+		info.Pages = append(info.Pages, PageInfo{
+			Index:   page.Index,
+			Name:    page.Name,
+			Title:   title,
+			Aliases: append([]string{}, page.Aliases...),
+			Path:    page.Path,
+		})
+	}
+	return info, base
+}
+
+// getNextPageIndex returns the page to show for the action based on the active pages of the dashboard.
+// Active pages with a priority preempt the others for all actions except goto.
+func (s *Server) getNextPageIndex(dashboard string, config *DashboardConfig, active []bool, lastPage int, action Action, target string) (int, error) {
+	if len(config.Pages) == 0 {
+		return 0, fmt.Errorf("dashboard has no pages")
 	}
 
-	var pageIndex int
+	if action == ActionGoto {
+		// goto ignores rotation rules since it is an explicit request for a specific page
+		pageIndex, err := s.findPageIndex(dashboard, config, target)
+		if err != nil {
+			return 0, fmt.Errorf("failed to find target page: %w", err)
+		}
+		return pageIndex, nil
+	}
+
+	active = preemptPages(config.Pages, active)
 	switch action {
 	case ActionRefresh:
-		pageIndex = lastPage
-	case ActionNextPage:
-		if lastPage+1 >= len(config.Pages) {
-			pageIndex = 0
-		} else {
-			pageIndex = lastPage + 1
+		if lastPage >= 0 && lastPage < len(active) && active[lastPage] {
+			return lastPage, nil
 		}
+		return nextActivePage(active, lastPage, 1), nil
+	case ActionNextPage:
+		return nextActivePage(active, lastPage, 1), nil
 	case ActionLastPage:
-		pageIndex = len(config.Pages) - 1
+		return nextActivePage(active, 0, -1), nil
 	case ActionPrevPage:
-		if lastPage-1 < 0 {
-			pageIndex = len(config.Pages) - 1
-		} else {
-			pageIndex = lastPage - 1
-		}
+		return nextActivePage(active, lastPage, -1), nil
 	case ActionFirstPage:
-		pageIndex = 0
+		return nextActivePage(active, len(active)-1, 1), nil
 	default:
 		return 0, fmt.Errorf("unknown action: %s", action)
 	}
+}
+
+// PageTiming tells devices how often to refresh a page and how long to show it before moving to the next page.
+type PageTiming struct {
+	RefreshInterval time.Duration
+	DwellTime       time.Duration
+}
+
+// getPageTiming resolves the timing of a page from the page config, the page frontmatter and the dashboard config in this order.
+func (s *Server) getPageTiming(dashboard string, config *DashboardConfig, pageIndex int) PageTiming {
+	timing := PageTiming{
+		RefreshInterval: config.RefreshInterval,
+		DwellTime:       config.DwellTime,
+	}
+	if pageIndex < 0 || pageIndex >= len(config.Pages) {
+		return timing
+	}
+
+	pageConfig := config.Pages[pageIndex]
+	pages, err := s.dashboardPages(dashboard)
+	if err != nil {
+		slog.Error("failed to load page for timing", slog.String("dashboard", dashboard), slog.Int("page", pageIndex), slog.Any("err", err))
+	} else if pageIndex < len(pages) {
+		page := pages[pageIndex]
+		if d, ok := durationVar(page.Vars, "refresh_interval"); ok {
+			timing.RefreshInterval = d
+		}
+		if d, ok := durationVar(page.Vars, "dwell_time"); ok {
+			timing.DwellTime = d
+		}
+	}
+
+	if pageConfig.RefreshInterval > 0 {
+		timing.RefreshInterval = pageConfig.RefreshInterval
+	}
+	if pageConfig.DwellTime > 0 {
+		timing.DwellTime = pageConfig.DwellTime
+	}
+	return timing
+}
+
+// durationVar reads a duration from frontmatter vars, either as a duration string like `5m` or as a number of seconds.
+func durationVar(vars map[string]any, key string) (time.Duration, bool) {
+	switch v := vars[key].(type) {
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, false
+		}
+		return d, true
+	case int:
+		return time.Duration(v) * time.Second, true
+	case int64:
+		// TOML frontmatter decodes integers as int64
+		return time.Duration(v) * time.Second, true
+	case float64:
+		return time.Duration(v * float64(time.Second)), true
+	default:
+		return 0, false
+	}
+}
 
-	return pageIndex, nil
+// pageName returns the name of a page derived from its path, this is also the name of the page template.
+func pageName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
 }
 
 type Page struct {
-	Name  string
-	Index int
-	Vars  map[string]any
-	Body  []byte
+	Path    string
+	Name    string
+	Aliases []string
+	Index   int
+	Vars    map[string]any
+	Body    []byte
 }
 
 type Base struct {
+	Name      string
 	Vars      map[string]any
 	Body      []byte
 	PageIndex int
 	Pages     []Page
 	Config    DashboardConfig
+
+	// templates are the pre-parsed templates of each page, nil if the dashboard was read from disk directly
+	templates []*template.Template
 }
 
+// loadDashboard returns the currently loaded version of a dashboard with the given page selected.
 func (s *Server) loadDashboard(dashboard string, pageIndex int) (*Base, error) {
-	config, err := s.getDashboardConfig(dashboard)
+	base, err := s.cachedDashboard(dashboard)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get dashboard config: %w", err)
+		return nil, err
 	}
 
-	if pageIndex < 0 || pageIndex >= len(config.Pages) {
+	if pageIndex < 0 || pageIndex >= len(base.Pages) {
 		return nil, fmt.Errorf("invalid page index: %d", pageIndex)
 	}
 
-	baseFile, err := os.Open(filepath.Join(s.cfg.DashboardDir, dashboard, config.Base))
+	page := *base
+	page.PageIndex = pageIndex
+	return &page, nil
+}
+
+// readDashboard reads the config, base & page files of a dashboard from disk.
+func (s *Server) readDashboard(dashboard string) (*Base, error) {
+	config, err := s.readDashboardConfig(dashboard)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dashboard config: %w", err)
+	}
+
+	baseFile, err := os.Open(filepath.Join(s.config().DashboardDir, dashboard, config.Base))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
@@ -132,8 +530,8 @@ func (s *Server) loadDashboard(dashboard string, pageIndex int) (*Base, error) {
 	}
 
 	var pages []Page
-	for i, pageName := range config.Pages {
-		page, err := s.loadPage(dashboard, i, pageName)
+	for i, pageConfig := range config.Pages {
+		page, err := s.loadPage(dashboard, i, pageConfig)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load page: %w", err)
 		}
@@ -141,16 +539,17 @@ func (s *Server) loadDashboard(dashboard string, pageIndex int) (*Base, error) {
 	}
 
 	return &Base{
+		Name:      dashboard,
 		Vars:      baseFrontmatter,
 		Body:      baseBody,
-		PageIndex: pageIndex,
+		PageIndex: 0,
 		Pages:     pages,
 		Config:    *config,
 	}, nil
 }
 
-func (s *Server) loadPage(dashboard string, i int, pageName string) (*Page, error) {
-	pageFile, err := os.Open(filepath.Join(s.cfg.DashboardDir, dashboard, pageName))
+func (s *Server) loadPage(dashboard string, i int, pageConfig PageConfig) (*Page, error) {
+	pageFile, err := os.Open(filepath.Join(s.config().DashboardDir, dashboard, pageConfig.Path))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
@@ -162,10 +561,55 @@ func (s *Server) loadPage(dashboard string, i int, pageName string) (*Page, erro
 		return nil, fmt.Errorf("failed to parse frontmatter: %w", err)
 	}
 
+	// the name is taken from the page config, the frontmatter or the file name in this order
+	name := pageConfig.Name
+	if name == "" {
+		name, _ = pageFrontmatter["name"].(string)
+	}
+	if name == "" {
+		name = pageName(pageConfig.Path)
+	}
+
+	aliases := slices.Clone(pageConfig.Aliases)
+	if frontmatterAliases, ok := pageFrontmatter["aliases"].([]any); ok {
+		for _, alias := range frontmatterAliases {
+			if aliasStr, ok := alias.(string); ok {
+				aliases = append(aliases, aliasStr)
+			}
+		}
+	}
+
 	return &Page{
-		Name:  pageName,
-		Index: i,
-		Vars:  pageFrontmatter,
-		Body:  pageBody,
+		Path:    pageConfig.Path,
+		Name:    name,
+		Aliases: aliases,
+		Index:   i,
+		Vars:    pageFrontmatter,
+		Body:    pageBody,
 	}, nil
 }
+
+// findPageIndex resolves a page index or a page name/alias to the index of the page.
+func (s *Server) findPageIndex(dashboard string, config *DashboardConfig, page string) (int, error) {
+	if index, err := strconv.Atoi(page); err == nil {
+		if index < 0 || index >= len(config.Pages) {
+			return 0, fmt.Errorf("invalid page index: %d", index)
+		}
+		return index, nil
+	}
+
+	pages, err := s.dashboardPages(dashboard)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load pages: %w", err)
+	}
+	for i, p := range pages {
+		// the pages may be from a newer version of the dashboard than the config
+		if i >= len(config.Pages) {
+			break
+		}
+		if p.Name == page || slices.Contains(p.Aliases, page) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown page: %s", page)
+}
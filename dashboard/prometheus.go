@@ -0,0 +1,50 @@
+package dashboard
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/topi314/esphome-dashboard/dashboard/prometheus"
+)
+
+func (s *Server) fetchPrometheusQueries(ctx context.Context, config *PrometheusConfig) map[string]prometheus.Result {
+	results := make(map[string]prometheus.Result)
+	if config == nil {
+		return results
+	}
+
+	client := prometheus.New(config.URL, config.Token, s.httpClient)
+	for _, query := range config.Queries {
+		cacheKey := "prometheus:" + config.URL + "\n" + query.Query + "\n" + query.Range.String() + "\n" + query.Step.String()
+		if value, ok := s.cache.get(cacheKey); ok {
+			results[query.Name] = value.(prometheus.Result)
+			continue
+		}
+
+		var (
+			result prometheus.Result
+			err    error
+		)
+		now := time.Now()
+		if query.Range > 0 {
+			step := query.Step
+			if step <= 0 {
+				// default to 60 data points
+				step = query.Range / 60
+			}
+			result, err = client.QueryRange(ctx, query.Query, now.Add(-query.Range), now, step)
+		} else {
+			result, err = client.Query(ctx, query.Query, now)
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to query prometheus", slog.String("query", query.Name), slog.String("promql", query.Query), slog.Any("err", err))
+			continue
+		}
+
+		s.cache.set(cacheKey, result, query.CacheTTL)
+		results[query.Name] = result
+	}
+
+	return results
+}
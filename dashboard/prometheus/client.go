@@ -0,0 +1,130 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func New(url string, token string, client *http.Client) *Client {
+	return &Client{
+		url:    strings.TrimSuffix(url, "/"),
+		token:  token,
+		client: client,
+	}
+}
+
+type Client struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// Query executes an instant query at the given time.
+func (c *Client) Query(ctx context.Context, query string, t time.Time) (Result, error) {
+	v := url.Values{
+		"query": {query},
+		"time":  {formatTime(t)},
+	}
+
+	return c.do(ctx, "/api/v1/query", v)
+}
+
+// QueryRange executes a range query between start and end with the given resolution step.
+func (c *Client) QueryRange(ctx context.Context, query string, start time.Time, end time.Time, step time.Duration) (Result, error) {
+	v := url.Values{
+		"query": {query},
+		"start": {formatTime(start)},
+		"end":   {formatTime(end)},
+		"step":  {strconv.FormatFloat(step.Seconds(), 'f', -1, 64)},
+	}
+
+	return c.do(ctx, "/api/v1/query_range", v)
+}
+
+func (c *Client) do(ctx context.Context, path string, v url.Values) (Result, error) {
+	rq, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+path+"?"+v.Encode(), nil)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create query request: %w", err)
+	}
+	if c.token != "" {
+		rq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	slog.DebugContext(ctx, "Sending request to Prometheus", slog.String("url", rq.URL.String()))
+	rs, err := c.client.Do(rq)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to query: %w", err)
+	}
+	defer rs.Body.Close()
+
+	var response response
+	if err = json.NewDecoder(rs.Body).Decode(&response); err != nil {
+		return Result{}, fmt.Errorf("failed to decode query response: %s: %w", rs.Status, err)
+	}
+
+	if response.Status != "success" {
+		return Result{}, fmt.Errorf("failed to query: %s: %s", response.ErrorType, response.Error)
+	}
+
+	var d data
+	if err = json.Unmarshal(response.Data, &d); err != nil {
+		return Result{}, fmt.Errorf("failed to decode query data: %w", err)
+	}
+
+	return decodeResult(d)
+}
+
+func decodeResult(d data) (Result, error) {
+	result := Result{
+		Type: d.ResultType,
+	}
+
+	switch d.ResultType {
+	case ResultTypeVector, ResultTypeMatrix:
+		var raw []series
+		if err := json.Unmarshal(d.Result, &raw); err != nil {
+			return Result{}, fmt.Errorf("failed to decode %s result: %w", d.ResultType, err)
+		}
+
+		allSeries := make([]Series, 0, len(raw))
+		for _, r := range raw {
+			s := Series{
+				Metric: r.Metric,
+				Values: r.Values,
+			}
+			if r.Value != nil {
+				s.Value = *r.Value
+			}
+			allSeries = append(allSeries, s)
+		}
+
+		if d.ResultType == ResultTypeVector {
+			result.Vector = allSeries
+		} else {
+			result.Matrix = allSeries
+		}
+	case ResultTypeScalar:
+		if err := json.Unmarshal(d.Result, &result.Scalar); err != nil {
+			return Result{}, fmt.Errorf("failed to decode scalar result: %w", err)
+		}
+	case ResultTypeString:
+		if err := json.Unmarshal(d.Result, &result.String); err != nil {
+			return Result{}, fmt.Errorf("failed to decode string result: %w", err)
+		}
+	default:
+		return Result{}, fmt.Errorf("unsupported result type: %s", d.ResultType)
+	}
+
+	return result, nil
+}
+
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixMilli())/1000, 'f', -1, 64)
+}
@@ -0,0 +1,261 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubServer returns a server which answers every request with the given status & body and records the last request.
+func stubServer(t *testing.T, status int, body string) (*httptest.Server, *http.Request) {
+	t.Helper()
+
+	var last http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = *r.Clone(context.Background())
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &last
+}
+
+func TestClientQueryVector(t *testing.T) {
+	srv, rq := stubServer(t, http.StatusOK, `{
+		"status": "success",
+		"data": {
+			"resultType": "vector",
+			"result": [
+				{"metric": {"__name__": "up", "instance": "nas:9100"}, "value": [1435781451.781, "1"]},
+				{"metric": {"__name__": "up", "instance": "pi:9100"}, "value": [1435781451.781, "0"]}
+			]
+		}
+	}`)
+
+	client := New(srv.URL+"/", "secret", srv.Client())
+	result, err := client.Query(context.Background(), "up", time.UnixMilli(1435781451781))
+	if err != nil {
+		t.Fatalf("failed to query: %s", err)
+	}
+
+	if rq.URL.Path != "/api/v1/query" {
+		t.Errorf("unexpected path: %s", rq.URL.Path)
+	}
+	if query := rq.URL.Query(); query.Get("query") != "up" || query.Get("time") != "1435781451.781" {
+		t.Errorf("unexpected query: %s", rq.URL.RawQuery)
+	}
+	if auth := rq.Header.Get("Authorization"); auth != "Bearer secret" {
+		t.Errorf("unexpected authorization header: %s", auth)
+	}
+
+	if result.Type != ResultTypeVector {
+		t.Fatalf("unexpected result type: %s", result.Type)
+	}
+	if len(result.Vector) != 2 {
+		t.Fatalf("expected 2 series, got %d", len(result.Vector))
+	}
+	if instance := result.Vector[1].Metric["instance"]; instance != "pi:9100" {
+		t.Errorf("unexpected instance: %s", instance)
+	}
+	if result.Value() != 1 {
+		t.Errorf("unexpected value: %v", result.Value())
+	}
+	if floats := result.Vector[1].Floats(); len(floats) != 1 || floats[0] != 0 {
+		t.Errorf("unexpected floats: %v", floats)
+	}
+}
+
+func TestClientQueryRangeMatrix(t *testing.T) {
+	srv, rq := stubServer(t, http.StatusOK, `{
+		"status": "success",
+		"data": {
+			"resultType": "matrix",
+			"result": [
+				{"metric": {"mountpoint": "/"}, "values": [[1435781430, "0.5"], [1435781445, "0.75"], [1435781460, "1e-1"]]}
+			]
+		}
+	}`)
+
+	client := New(srv.URL, "", srv.Client())
+	start := time.Unix(1435781430, 0)
+	result, err := client.QueryRange(context.Background(), "disk_usage", start, start.Add(30*time.Second), 15*time.Second)
+	if err != nil {
+		t.Fatalf("failed to query: %s", err)
+	}
+
+	if rq.URL.Path != "/api/v1/query_range" {
+		t.Errorf("unexpected path: %s", rq.URL.Path)
+	}
+	query := rq.URL.Query()
+	if query.Get("start") != "1435781430" || query.Get("end") != "1435781460" || query.Get("step") != "15" {
+		t.Errorf("unexpected query: %s", rq.URL.RawQuery)
+	}
+	if auth := rq.Header.Get("Authorization"); auth != "" {
+		t.Errorf("expected no authorization header, got %s", auth)
+	}
+
+	if result.Type != ResultTypeMatrix {
+		t.Fatalf("unexpected result type: %s", result.Type)
+	}
+	series := result.Series()
+	if len(series) != 1 {
+		t.Fatalf("expected 1 series, got %d", len(series))
+	}
+	floats := series[0].Floats()
+	if len(floats) != 3 || floats[0] != 0.5 || floats[1] != 0.75 || floats[2] != 0.1 {
+		t.Errorf("unexpected floats: %v", floats)
+	}
+	if !series[0].Values[1].Time.Equal(time.Unix(1435781445, 0)) {
+		t.Errorf("unexpected sample time: %s", series[0].Values[1].Time)
+	}
+	if !math.IsNaN(result.Value()) {
+		t.Errorf("expected NaN value for matrix result, got %v", result.Value())
+	}
+}
+
+func TestClientQueryScalar(t *testing.T) {
+	srv, _ := stubServer(t, http.StatusOK, `{"status": "success", "data": {"resultType": "scalar", "result": [1435781451.781, "42"]}}`)
+
+	result, err := New(srv.URL, "", srv.Client()).Query(context.Background(), "42", time.Now())
+	if err != nil {
+		t.Fatalf("failed to query: %s", err)
+	}
+
+	if result.Type != ResultTypeScalar {
+		t.Fatalf("unexpected result type: %s", result.Type)
+	}
+	if result.Value() != 42 {
+		t.Errorf("unexpected value: %v", result.Value())
+	}
+	if len(result.Series()) != 0 {
+		t.Errorf("expected no series, got %v", result.Series())
+	}
+}
+
+func TestClientQueryString(t *testing.T) {
+	srv, _ := stubServer(t, http.StatusOK, `{"status": "success", "data": {"resultType": "string", "result": [1435781451.781, "foo"]}}`)
+
+	result, err := New(srv.URL, "", srv.Client()).Query(context.Background(), `"foo"`, time.Now())
+	if err != nil {
+		t.Fatalf("failed to query: %s", err)
+	}
+
+	if result.Type != ResultTypeString {
+		t.Fatalf("unexpected result type: %s", result.Type)
+	}
+	if result.String.Value != "foo" {
+		t.Errorf("unexpected value: %s", result.String.Value)
+	}
+	if !result.String.Time.Equal(time.UnixMilli(1435781451781)) {
+		t.Errorf("unexpected time: %s", result.String.Time)
+	}
+	if !math.IsNaN(result.Value()) {
+		t.Errorf("expected NaN value for string result, got %v", result.Value())
+	}
+}
+
+func TestClientQueryError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		err    string
+	}{
+		{
+			name:   "bad query",
+			status: http.StatusBadRequest,
+			body:   `{"status": "error", "errorType": "bad_data", "error": "parse error at char 4: unexpected end of input"}`,
+			err:    "bad_data: parse error at char 4",
+		},
+		{
+			name:   "invalid body",
+			status: http.StatusBadGateway,
+			body:   `<html>Bad Gateway</html>`,
+			err:    "502 Bad Gateway",
+		},
+		{
+			name:   "unknown result type",
+			status: http.StatusOK,
+			body:   `{"status": "success", "data": {"resultType": "histogram", "result": []}}`,
+			err:    "unsupported result type: histogram",
+		},
+		{
+			name:   "invalid sample",
+			status: http.StatusOK,
+			body:   `{"status": "success", "data": {"resultType": "vector", "result": [{"metric": {}, "value": [1435781451.781, "abc"]}]}}`,
+			err:    "failed to parse sample value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, _ := stubServer(t, tt.status, tt.body)
+
+			_, err := New(srv.URL, "", srv.Client()).Query(context.Background(), "up", time.Now())
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error containing %q, got %q", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestSampleUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		time  time.Time
+		value float64
+		err   bool
+	}{
+		{name: "integer timestamp", data: `[1435781430, "1.5"]`, time: time.Unix(1435781430, 0), value: 1.5},
+		{name: "fractional timestamp", data: `[1435781451.781, "-2"]`, time: time.UnixMilli(1435781451781), value: -2},
+		{name: "positive infinity", data: `[1435781430, "+Inf"]`, time: time.Unix(1435781430, 0), value: math.Inf(1)},
+		{name: "not an array", data: `{"value": "1"}`, err: true},
+		{name: "numeric value", data: `[1435781430, 1]`, err: true},
+		{name: "string timestamp", data: `["1435781430", "1"]`, err: true},
+		{name: "invalid value", data: `[1435781430, "one"]`, err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sample Sample
+			err := json.Unmarshal([]byte(tt.data), &sample)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", sample)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to unmarshal sample: %s", err)
+			}
+
+			if !sample.Time.Equal(tt.time) {
+				t.Errorf("expected time %s, got %s", tt.time, sample.Time)
+			}
+			if sample.Value != tt.value {
+				t.Errorf("expected value %v, got %v", tt.value, sample.Value)
+			}
+		})
+	}
+
+	t.Run("NaN", func(t *testing.T) {
+		var sample Sample
+		if err := json.Unmarshal([]byte(`[1435781430, "NaN"]`), &sample); err != nil {
+			t.Fatalf("failed to unmarshal sample: %s", err)
+		}
+		if !math.IsNaN(sample.Value) {
+			t.Errorf("expected NaN, got %v", sample.Value)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package prometheus
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+type ResultType string
+
+const (
+	ResultTypeVector ResultType = "vector"
+	ResultTypeMatrix ResultType = "matrix"
+	ResultTypeScalar ResultType = "scalar"
+	ResultTypeString ResultType = "string"
+)
+
+type response struct {
+	Status    string          `json:"status"`
+	Data      json.RawMessage `json:"data"`
+	ErrorType string          `json:"errorType"`
+	Error     string          `json:"error"`
+}
+
+type data struct {
+	ResultType ResultType      `json:"resultType"`
+	Result     json.RawMessage `json:"result"`
+}
+
+type series struct {
+	Metric map[string]string `json:"metric"`
+	Value  *Sample           `json:"value"`
+	Values []Sample          `json:"values"`
+}
+
+// Result is the result of an instant or range query.
+// Depending on the Type either Vector, Matrix, Scalar or String is set.
+type Result struct {
+	Type   ResultType
+	Vector []Series
+	Matrix []Series
+	Scalar Sample
+	String StringSample
+}
+
+// Value returns the value of a scalar result or the value of the first series of a vector result.
+func (r Result) Value() float64 {
+	switch r.Type {
+	case ResultTypeScalar:
+		return r.Scalar.Value
+	case ResultTypeVector:
+		if len(r.Vector) > 0 {
+			return r.Vector[0].Value.Value
+		}
+	}
+	return math.NaN()
+}
+
+// Series returns the series of a vector or matrix result.
+func (r Result) Series() []Series {
+	if r.Type == ResultTypeMatrix {
+		return r.Matrix
+	}
+	return r.Vector
+}
+
+type Series struct {
+	Metric map[string]string
+	// Value is the sample of a vector result
+	Value Sample
+	// Values are the samples of a matrix result
+	Values []Sample
+}
+
+// Floats returns the values of all samples, this is useful for charts.
+func (s Series) Floats() []float64 {
+	if len(s.Values) == 0 {
+		return []float64{s.Value.Value}
+	}
+
+	values := make([]float64, len(s.Values))
+	for i, sample := range s.Values {
+		values[i] = sample.Value
+	}
+	return values
+}
+
+// StringSample is the sample of a string result.
+type StringSample struct {
+	Time  time.Time
+	Value string
+}
+
+func (s *StringSample) UnmarshalJSON(data []byte) error {
+	t, rawValue, err := decodeSample(data)
+	if err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(rawValue, &s.Value); err != nil {
+		return fmt.Errorf("failed to decode sample value: %w", err)
+	}
+
+	s.Time = t
+	return nil
+}
+
+type Sample struct {
+	Time  time.Time
+	Value float64
+}
+
+func (s *Sample) UnmarshalJSON(data []byte) error {
+	t, rawValue, err := decodeSample(data)
+	if err != nil {
+		return err
+	}
+
+	var value string
+	if err = json.Unmarshal(rawValue, &value); err != nil {
+		return fmt.Errorf("failed to decode sample value: %w", err)
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("failed to parse sample value: %w", err)
+	}
+
+	s.Time = t
+	s.Value = parsed
+	return nil
+}
+
+// decodeSample decodes a [timestamp, value] pair and returns the time & the raw value.
+func decodeSample(data []byte) (time.Time, json.RawMessage, error) {
+	var raw [2]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return time.Time{}, nil, fmt.Errorf("failed to decode sample: %w", err)
+	}
+
+	var timestamp float64
+	if err := json.Unmarshal(raw[0], &timestamp); err != nil {
+		return time.Time{}, nil, fmt.Errorf("failed to decode sample timestamp: %w", err)
+	}
+
+	// timestamps have millisecond precision, rounding avoids float errors like .780999898 for .781
+	return time.UnixMilli(int64(math.Round(timestamp * 1000))), raw[1], nil
+}
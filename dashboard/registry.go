@@ -0,0 +1,191 @@
+package dashboard
+
+import (
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// dashboardWatchInterval is how often the dashboard dir is checked for changed files.
+const dashboardWatchInterval = 2 * time.Second
+
+// dashboardRegistry holds the loaded dashboards with their pre-parsed templates.
+type dashboardRegistry struct {
+	mu         sync.RWMutex
+	dashboards map[string]*registryEntry
+}
+
+type registryEntry struct {
+	// base is the last successfully loaded version, nil if the dashboard never loaded
+	base *Base
+	// err is the error of the last load
+	err   error
+	files map[string]fileStat
+}
+
+// registryEntry returns the registry entry of a dashboard, false if it's not in the registry, e.g. in dev mode or for CLI commands.
+func (s *Server) registryEntry(dashboard string) (*registryEntry, bool) {
+	s.registry.mu.RLock()
+	defer s.registry.mu.RUnlock()
+
+	entry, ok := s.registry.dashboards[dashboard]
+	return entry, ok
+}
+
+// cachedDashboard returns the last successfully loaded version of a dashboard with its templates parsed for rendering.
+// Dashboards which are not in the registry are read from disk & their templates are parsed on every call.
+func (s *Server) cachedDashboard(dashboard string) (*Base, error) {
+	entry, ok := s.registryEntry(dashboard)
+	if !ok {
+		return s.compileDashboard(dashboard)
+	}
+	if entry.base == nil {
+		return nil, entry.err
+	}
+	return entry.base, nil
+}
+
+// loadedDashboard returns the last successfully loaded version of a dashboard for reading its config & pages.
+// Dashboards which are not in the registry are read from disk without parsing their templates.
+func (s *Server) loadedDashboard(dashboard string) (*Base, error) {
+	entry, ok := s.registryEntry(dashboard)
+	if !ok {
+		return s.readDashboard(dashboard)
+	}
+	if entry.base == nil {
+		return nil, entry.err
+	}
+	return entry.base, nil
+}
+
+// dashboardPages returns the pages of the last successfully loaded version of a dashboard.
+func (s *Server) dashboardPages(dashboard string) ([]Page, error) {
+	base, err := s.loadedDashboard(dashboard)
+	if err != nil {
+		return nil, err
+	}
+	return base.Pages, nil
+}
+
+// dashboardReloadError returns the error of the last load of a dashboard in the registry.
+func (s *Server) dashboardReloadError(dashboard string) error {
+	s.registry.mu.RLock()
+	defer s.registry.mu.RUnlock()
+
+	if entry, ok := s.registry.dashboards[dashboard]; ok {
+		return entry.err
+	}
+	return nil
+}
+
+// compileDashboard reads a dashboard from disk and parses the templates of all pages.
+func (s *Server) compileDashboard(dashboard string) (*Base, error) {
+	base, err := s.readDashboard(dashboard)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make([]*template.Template, len(base.Pages))
+	for i := range base.Pages {
+		page := *base
+		page.PageIndex = i
+		if templates[i], err = s.parseTemplates(page); err != nil {
+			return nil, err
+		}
+	}
+	base.templates = templates
+
+	return base, nil
+}
+
+// reloadDashboards loads all dashboards whose files changed since the last load and drops removed dashboards.
+// Dashboards which fail to load keep serving their last good version.
+func (s *Server) reloadDashboards() {
+	dashboardDir := s.config().DashboardDir
+	dashboards, err := s.dashboards()
+	if err != nil {
+		slog.Error("failed to list dashboards", slog.Any("err", err))
+		return
+	}
+
+	s.registry.mu.RLock()
+	oldEntries := s.registry.dashboards
+	s.registry.mu.RUnlock()
+
+	entries := make(map[string]*registryEntry, len(dashboards))
+	for _, dashboard := range dashboards {
+		files := dashboardFiles(filepath.Join(dashboardDir, dashboard))
+		oldEntry, ok := oldEntries[dashboard]
+		if ok && maps.Equal(oldEntry.files, files) {
+			entries[dashboard] = oldEntry
+			continue
+		}
+
+		base, err := s.compileDashboard(dashboard)
+		entry := &registryEntry{
+			base:  base,
+			err:   err,
+			files: files,
+		}
+		if err != nil {
+			s.metrics.dashboardReloads.Inc(dashboard, "error")
+			if ok && oldEntry.base != nil {
+				entry.base = oldEntry.base
+				slog.Error("failed to reload dashboard, keeping the last good version", slog.String("dashboard", dashboard), slog.Any("err", err))
+			} else {
+				slog.Error("failed to load dashboard", slog.String("dashboard", dashboard), slog.Any("err", err))
+			}
+		} else {
+			s.metrics.dashboardReloads.Inc(dashboard, "success")
+			slog.Info("loaded dashboard", slog.String("dashboard", dashboard), slog.Int("pages", len(base.Pages)))
+		}
+		entries[dashboard] = entry
+	}
+
+	for dashboard := range oldEntries {
+		if _, ok := entries[dashboard]; !ok {
+			slog.Info("removed dashboard", slog.String("dashboard", dashboard))
+		}
+	}
+
+	s.registry.mu.Lock()
+	s.registry.dashboards = entries
+	s.registry.mu.Unlock()
+}
+
+// watchDashboards reloads changed dashboards until the server is stopped.
+func (s *Server) watchDashboards() {
+	ticker := time.NewTicker(dashboardWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopped:
+			return
+		case <-ticker.C:
+			s.reloadDashboards()
+		}
+	}
+}
+
+// dashboardFiles returns the stats of all files in the dashboard dir to detect changes.
+func dashboardFiles(dir string) map[string]fileStat {
+	files := make(map[string]fileStat)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		files[path] = statFile(path)
+		return nil
+	})
+	if err != nil {
+		// an unreadable dir always counts as changed so it is retried
+		files[dir] = fileStat{modTime: time.Now()}
+		slog.Error("failed to read dashboard dir", slog.String("dir", dir), slog.Any("err", err))
+	}
+	return files
+}
@@ -0,0 +1,47 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestControlWithoutRegistryIgnoresTemplates(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.toml": `width = 800
+height = 480
+base = "base.gohtml"
+pages = ["a.gohtml", "b.gohtml"]
+`,
+		"base.gohtml": "{{ template \"page\" . }}",
+		"a.gohtml":    "a",
+		// the broken template must only affect rendering the page
+		"b.gohtml": "{{ .Broken ",
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "default"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, "default", name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := defaultConfig()
+	cfg.DashboardDir = dir
+	// the registry is only filled when the server is started, like in dev mode & for CLI commands
+	s := New(cfg, "test", "go", os.DirFS(".."))
+
+	w := httptest.NewRecorder()
+	s.Routes().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dashboards/default/control?action=next_page&page=0", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "1" {
+		t.Fatalf("unexpected response %d: %s", w.Code, w.Body.String())
+	}
+
+	if _, err := s.loadDashboard("default", 1); err == nil {
+		t.Error("expected loading the dashboard for rendering to fail")
+	}
+}
@@ -3,6 +3,7 @@ package dashboard
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"html/template"
 	"image"
@@ -11,15 +12,19 @@ import (
 	"image/png"
 	"io"
 	"log/slog"
-	"path/filepath"
-	"strings"
+	"net"
+	"net/http"
 	"time"
 
+	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/chromedp"
 	"github.com/sergeymakinen/go-bmp"
 
+	"github.com/topi314/esphome-dashboard/dashboard/feed"
 	"github.com/topi314/esphome-dashboard/dashboard/homeassistant"
+	"github.com/topi314/esphome-dashboard/dashboard/mqtt"
+	"github.com/topi314/esphome-dashboard/dashboard/prometheus"
 )
 
 type RenderData struct {
@@ -28,6 +33,10 @@ type RenderData struct {
 	Pages         []PageRenderData
 	Vars          map[string]any
 	HomeAssistant HomeAssistantRenderData
+	Sources       map[string]any
+	MQTT          map[string]mqtt.Message
+	Feeds         map[string][]feed.Item
+	Prometheus    map[string]prometheus.Result
 }
 
 func (r RenderData) Page() PageRenderData {
@@ -35,14 +44,17 @@ func (r RenderData) Page() PageRenderData {
 }
 
 type PageRenderData struct {
-	Index int
-	Vars  map[string]any
+	Index   int
+	Name    string
+	Aliases []string
+	Vars    map[string]any
 }
 
 type HomeAssistantRenderData struct {
 	Entities  map[string]homeassistant.EntityState
 	Calendars map[string][]CalendarDay
 	Services  map[string]homeassistant.Response
+	Todos     map[string][]homeassistant.TodoItem
 }
 
 type CalendarDay struct {
@@ -71,52 +83,53 @@ func (s *Server) templateFuncs() template.FuncMap {
 		"formatTimeToHour":    formatTimeToHour,
 		"formatTimeToDay":     formatTimeToDay,
 		"formatTimeToRelDay":  formatTimeToRelDay,
+		"svgPoints":           svgPoints,
 	}
 }
 
 func (s *Server) executeDashboard(ctx context.Context, base Base) (io.Reader, int, error) {
-	baseTemplate, err := template.New("base").
-		Funcs(s.templateFuncs()).
-		Parse(string(base.Body))
+	start := time.Now()
+	defer func() {
+		s.metrics.executeDuration.Observe(time.Since(start).Seconds(), base.Name)
+	}()
+
+	baseTemplate, err := s.baseTemplate(base)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to parse base template: %w", err)
+		return nil, 0, err
 	}
 
+	slog.DebugContext(ctx, "loaded templates", slog.String("templates", baseTemplate.DefinedTemplates()))
+
 	var pageRenderData []PageRenderData
 	for _, p := range base.Pages {
-		_, err = baseTemplate.New(strings.TrimSuffix(filepath.Base(p.Name), filepath.Ext(p.Name))).
-			Funcs(s.templateFuncs()).
-			Parse(string(p.Body))
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to parse page template: %w", err)
-		}
-
 		pageRenderData = append(pageRenderData, PageRenderData{
-			Index: p.Index,
-			Vars:  p.Vars,
+			Index:   p.Index,
+			Name:    p.Name,
+			Aliases: p.Aliases,
+			Vars:    p.Vars,
 		})
 	}
 
-	if _, err = baseTemplate.New("page").
-		Funcs(s.templateFuncs()).
-		Parse(string(base.Pages[base.PageIndex].Body)); err != nil {
-		return nil, 0, fmt.Errorf("failed to parse page template: %w", err)
-	}
+	homeAssistantRenderData := s.fetchHomeAssistantData(ctx, base.Config.HomeAssistant)
+	sources := s.fetchHTTPSources(ctx, base.Config.HTTPSources)
+	feeds := s.fetchFeeds(ctx, base.Config.Feeds)
+	prometheusResults := s.fetchPrometheusQueries(ctx, base.Config.Prometheus)
 
-	if _, err = baseTemplate.ParseFS(s.templates, "templates/*.gohtml"); err != nil {
-		return nil, 0, fmt.Errorf("failed to parse templates: %w", err)
+	var mqttMessages map[string]mqtt.Message
+	if mqttClient := s.mqttClient(); mqttClient != nil {
+		mqttMessages = mqttClient.Messages()
 	}
 
-	slog.DebugContext(ctx, "loaded templates", slog.String("templates", baseTemplate.DefinedTemplates()))
-
-	homeAssistantRenderData := s.fetchHomeAssistantData(ctx, base.Config.HomeAssistant)
-
 	data := RenderData{
 		PageIndex:     base.PageIndex,
 		PageCount:     len(base.Config.Pages),
 		Pages:         pageRenderData,
 		Vars:          base.Vars,
 		HomeAssistant: homeAssistantRenderData,
+		Sources:       sources,
+		MQTT:          mqttMessages,
+		Feeds:         feeds,
+		Prometheus:    prometheusResults,
 	}
 
 	var buf bytes.Buffer
@@ -127,15 +140,137 @@ func (s *Server) executeDashboard(ctx context.Context, base Base) (io.Reader, in
 	return &buf, buf.Len(), nil
 }
 
+// baseTemplate returns the pre-parsed templates of the selected page or parses them if the dashboard was read from disk.
+func (s *Server) baseTemplate(base Base) (*template.Template, error) {
+	if base.templates != nil {
+		return base.templates[base.PageIndex], nil
+	}
+	return s.parseTemplates(base)
+}
+
+// parseTemplates parses the base, all page & the built-in templates of the dashboard.
+// The current page is additionally available as "page".
+func (s *Server) parseTemplates(base Base) (*template.Template, error) {
+	baseTemplate, err := template.New("base").
+		Funcs(s.templateFuncs()).
+		Parse(string(base.Body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse base template: %w", err)
+	}
+
+	for _, p := range base.Pages {
+		if _, err = baseTemplate.New(pageName(p.Path)).
+			Funcs(s.templateFuncs()).
+			Parse(string(p.Body)); err != nil {
+			return nil, fmt.Errorf("failed to parse page template %s: %w", p.Path, err)
+		}
+	}
+
+	if _, err = baseTemplate.New("page").
+		Funcs(s.templateFuncs()).
+		Parse(string(base.Pages[base.PageIndex].Body)); err != nil {
+		return nil, fmt.Errorf("failed to parse page template: %w", err)
+	}
+
+	if _, err = baseTemplate.ParseFS(s.templates, "templates/*.gohtml"); err != nil {
+		return nil, fmt.Errorf("failed to parse templates: %w", err)
+	}
+
+	return baseTemplate, nil
+}
+
+// Render renders a page of a dashboard without starting the server, e.g. to save snapshots from the command line.
+// The page can be an index, a name or an alias. Formats other than html start a headless chrome which loads the page from a listener on a random local port.
+func (s *Server) Render(ctx context.Context, dashboard string, page string, format string) (io.Reader, string, error) {
+	config, err := s.getDashboardConfig(dashboard)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get dashboard config: %w", err)
+	}
+
+	pageIndex, err := s.findPageIndex(dashboard, config, page)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid page: %w", err)
+	}
+
+	if mqttClient := s.mqttClient(); mqttClient != nil {
+		connectMQTT(mqttClient)
+	}
+
+	if format == "html" {
+		base, err := s.loadDashboard(dashboard, pageIndex)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to load dashboard: %w", err)
+		}
+		content, _, err := s.executeDashboard(ctx, *base)
+		if err != nil {
+			return nil, "", err
+		}
+		return content, "text/html; charset=utf-8", nil
+	}
+
+	chromeCtx, err := s.startChrome()
+	if err != nil {
+		return nil, "", err
+	}
+	defer s.closeChrome()
+	// canceling ctx stops chrome which cancels the render
+	stop := context.AfterFunc(ctx, s.closeChrome)
+	defer stop()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to listen: %w", err)
+	}
+	server := &http.Server{
+		Handler: s.handler,
+		BaseContext: func(_ net.Listener) context.Context {
+			return chromeCtx
+		},
+	}
+	go func() {
+		if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("failed to serve", slog.Any("err", err))
+		}
+	}()
+	defer server.Close()
+
+	s.mu.Lock()
+	s.renderAddr = fmt.Sprintf("localhost:%d", ln.Addr().(*net.TCPAddr).Port)
+	s.mu.Unlock()
+
+	content, _, contentType, err := s.renderDashboard(chromeCtx, dashboard, pageIndex, config.Width, config.Height, format)
+	if err != nil {
+		return nil, "", err
+	}
+	return content, contentType, nil
+}
+
 func (s *Server) renderDashboard(ctx context.Context, dashboard string, pageIndex int, width int, height int, format string) (io.Reader, int, string, error) {
 	var cancel context.CancelFunc
 	ctx, cancel = chromedp.NewContext(ctx)
-	defer cancel()
+	s.metrics.chromeTabs.Inc()
+	defer func() {
+		cancel()
+		s.metrics.chromeTabs.Dec()
+	}()
 
-	var res []byte
+	start := time.Now()
 	if err := chromedp.Run(ctx,
 		chromedp.EmulateViewport(int64(width), int64(height)),
-		chromedp.Navigate(fmt.Sprintf("http://localhost:%d/dashboards/%s/pages/%d?html=1", s.cfg.ListenPort, dashboard, pageIndex)),
+		network.SetCookie(internalTokenCookie, s.internalToken).
+			WithDomain("localhost").
+			WithPath("/").
+			WithHTTPOnly(true),
+		chromedp.Navigate(s.renderURL(dashboard, pageIndex)),
+	); err != nil {
+		s.metrics.renderErrors.Inc(dashboard)
+		return nil, 0, "", fmt.Errorf("failed to navigate to page: %w", err)
+	}
+	s.metrics.renderDuration.Observe(time.Since(start).Seconds(), dashboard, "navigate")
+
+	start = time.Now()
+	var res []byte
+	if err := chromedp.Run(ctx,
 		chromedp.ActionFunc(func(ctx context.Context) error {
 			var err error
 			res, err = page.CaptureScreenshot().
@@ -146,10 +281,20 @@ func (s *Server) renderDashboard(ctx context.Context, dashboard string, pageInde
 			return err
 		}),
 	); err != nil {
-		return nil, 0, "", fmt.Errorf("failed to run chromedp: %w", err)
+		s.metrics.renderErrors.Inc(dashboard)
+		return nil, 0, "", fmt.Errorf("failed to capture screenshot: %w", err)
+	}
+	s.metrics.renderDuration.Observe(time.Since(start).Seconds(), dashboard, "screenshot")
+
+	start = time.Now()
+	content, contentLength, contentType, err := s.reencodeImage(bytes.NewReader(res), format)
+	if err != nil {
+		s.metrics.renderErrors.Inc(dashboard)
+		return nil, 0, "", err
 	}
+	s.metrics.renderDuration.Observe(time.Since(start).Seconds(), dashboard, "reencode")
 
-	return s.reencodeImage(bytes.NewReader(res), format)
+	return content, contentLength, contentType, nil
 }
 
 func (s *Server) reencodeImage(r io.Reader, format string) (io.Reader, int, string, error) {
@@ -8,9 +8,22 @@ func (s *Server) Routes() http.Handler {
 	r := http.NewServeMux()
 
 	r.HandleFunc("GET /version", s.getVersion)
-	r.HandleFunc("GET /dashboards/{dashboard}/control", s.getControl)
-	r.HandleFunc("GET /dashboards/{dashboard}/pages/{page}", s.getPage)
-	r.HandleFunc("GET /dashboards/{dashboard}/assets/", s.getAsset)
+	r.HandleFunc("GET /healthz", s.getHealthz)
+	r.HandleFunc("GET /readyz", s.getReadyz)
+	r.HandleFunc("GET /metrics", s.auth(s.getMetrics))
+	r.HandleFunc("GET /status", s.auth(s.getStatus))
+	r.HandleFunc("GET /devices", s.auth(s.getDevices))
+	r.HandleFunc("GET /validate", s.auth(s.getValidate))
+	r.HandleFunc("GET /dashboards/{dashboard}/validate", s.auth(s.getDashboardValidate))
+	r.HandleFunc("GET /dashboards", s.auth(s.getDashboards))
+	r.HandleFunc("GET /dashboards/{dashboard}", s.auth(s.getDashboard))
+	r.HandleFunc("GET /dashboards/{dashboard}/control", s.auth(s.getControl))
+	r.HandleFunc("GET /v2/dashboards/{dashboard}/control", s.auth(s.getControlV2))
+	r.HandleFunc("GET /dashboards/{dashboard}/sleep", s.auth(s.getSleepDuration))
+	r.HandleFunc("POST /dashboards/{dashboard}/devices/{device}/show", s.auth(s.postShow))
+	r.HandleFunc("DELETE /dashboards/{dashboard}/devices/{device}/show", s.auth(s.deleteShow))
+	r.HandleFunc("GET /dashboards/{dashboard}/pages/{page}", s.auth(s.getPage))
+	r.HandleFunc("GET /dashboards/{dashboard}/assets/", s.auth(s.getAsset))
 
 	return r
 }
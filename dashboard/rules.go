@@ -0,0 +1,195 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// weekdays are the accepted weekday names of schedules, either the full name or its three-letter abbreviation.
+var weekdays = map[string]time.Weekday{
+	"mon":       time.Monday,
+	"monday":    time.Monday,
+	"tue":       time.Tuesday,
+	"tuesday":   time.Tuesday,
+	"wed":       time.Wednesday,
+	"wednesday": time.Wednesday,
+	"thu":       time.Thursday,
+	"thursday":  time.Thursday,
+	"fri":       time.Friday,
+	"friday":    time.Friday,
+	"sat":       time.Saturday,
+	"saturday":  time.Saturday,
+	"sun":       time.Sunday,
+	"sunday":    time.Sunday,
+}
+
+// activePages returns for each page whether its schedules & conditions allow it to be shown at the given time.
+// If no page is active all pages are considered active so devices always have something to show.
+func (s *Server) activePages(ctx context.Context, pages []PageConfig, now time.Time) []bool {
+	active := make([]bool, len(pages))
+	var anyActive bool
+	for i, page := range pages {
+		active[i] = isScheduled(page.Schedules, now) && s.checkConditions(ctx, page.Conditions)
+		anyActive = anyActive || active[i]
+	}
+
+	if !anyActive {
+		for i := range active {
+			active[i] = true
+		}
+	}
+	return active
+}
+
+// preemptPages restricts the active pages to the active pages with the highest priority if any active page has a priority.
+func preemptPages(pages []PageConfig, active []bool) []bool {
+	highest := highestActivePriority(pages, active)
+	if highest == 0 {
+		return active
+	}
+
+	preempted := make([]bool, len(active))
+	for i, page := range pages {
+		preempted[i] = active[i] && page.Priority == highest
+	}
+	return preempted
+}
+
+// highestActivePriority returns the highest priority of all active pages.
+func highestActivePriority(pages []PageConfig, active []bool) int {
+	var highest int
+	for i, page := range pages {
+		if active[i] && page.Priority > highest {
+			highest = page.Priority
+		}
+	}
+	return highest
+}
+
+// nextActivePage returns the next active page starting from (excluding) start in the given direction wrapping around.
+func nextActivePage(active []bool, start int, direction int) int {
+	n := len(active)
+	start = ((start % n) + n) % n
+	for i := 1; i <= n; i++ {
+		index := ((start+i*direction)%n + n) % n
+		if active[index] {
+			return index
+		}
+	}
+	return start
+}
+
+func isScheduled(schedules []ScheduleConfig, now time.Time) bool {
+	if len(schedules) == 0 {
+		return true
+	}
+
+	for _, schedule := range schedules {
+		matches, err := schedule.matches(now)
+		if err != nil {
+			slog.Error("invalid page schedule", slog.Any("err", err))
+			continue
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns whether the schedule includes the given time. A window where from is after to spans midnight.
+func (c ScheduleConfig) matches(now time.Time) (bool, error) {
+	if len(c.Weekdays) > 0 {
+		var found bool
+		for _, day := range c.Weekdays {
+			weekday, ok := weekdays[strings.ToLower(day)]
+			if !ok {
+				return false, fmt.Errorf("invalid weekday: %s", day)
+			}
+			if weekday == now.Weekday() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+
+	from, err := parseClock(c.From, 0)
+	if err != nil {
+		return false, err
+	}
+	to, err := parseClock(c.To, 24*time.Hour)
+	if err != nil {
+		return false, err
+	}
+
+	year, month, day := now.Date()
+	sinceMidnight := now.Sub(time.Date(year, month, day, 0, 0, 0, 0, now.Location()))
+	if from <= to {
+		return sinceMidnight >= from && sinceMidnight < to, nil
+	}
+	return sinceMidnight >= from || sinceMidnight < to, nil
+}
+
+// parseClock parses a time of day in the format 15:04 into the duration since midnight.
+func parseClock(s string, fallback time.Duration) (time.Duration, error) {
+	if s == "" {
+		return fallback, nil
+	}
+	if s == "24:00" {
+		return 24 * time.Hour, nil
+	}
+
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: %w", s, err)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func (s *Server) checkConditions(ctx context.Context, conditions []ConditionConfig) bool {
+	if len(conditions) == 0 {
+		return true
+	}
+	homeAssistant := s.homeAssistantClient()
+	if homeAssistant == nil {
+		slog.ErrorContext(ctx, "page conditions require home assistant to be configured")
+		return false
+	}
+
+	for _, condition := range conditions {
+		state, err := homeAssistant.GetState(ctx, condition.Entity)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to get entity state for page condition", slog.String("entity_id", condition.Entity), slog.Any("err", err))
+			return false
+		}
+
+		if len(condition.States) > 0 && !slices.Contains(condition.States, state.State) {
+			return false
+		}
+		if slices.Contains(condition.NotStates, state.State) {
+			return false
+		}
+	}
+	return true
+}
+
+// validate returns an error if the schedule contains an invalid weekday or time of day.
+func (c ScheduleConfig) validate() error {
+	for _, day := range c.Weekdays {
+		if _, ok := weekdays[strings.ToLower(day)]; !ok {
+			return fmt.Errorf("invalid weekday: %s", day)
+		}
+	}
+	if _, err := parseClock(c.From, 0); err != nil {
+		return err
+	}
+	_, err := parseClock(c.To, 24*time.Hour)
+	return err
+}
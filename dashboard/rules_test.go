@@ -0,0 +1,77 @@
+package dashboard
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleWeekdays(t *testing.T) {
+	// 2026-05-07 is a thursday
+	now := time.Date(2026, 5, 7, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		weekday string
+		matches bool
+		err     bool
+	}{
+		{weekday: "thu", matches: true},
+		{weekday: "Thursday", matches: true},
+		{weekday: "THU", matches: true},
+		{weekday: "mon", matches: false},
+		{weekday: "monday", matches: false},
+		{weekday: "thurs", err: true},
+		{weekday: "monkey", err: true},
+		{weekday: "th", err: true},
+		{weekday: "", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.weekday, func(t *testing.T) {
+			schedule := ScheduleConfig{Weekdays: []string{tt.weekday}}
+
+			if err := schedule.validate(); (err != nil) != tt.err {
+				t.Fatalf("expected validation error %t, got %v", tt.err, err)
+			}
+			matches, err := schedule.matches(now)
+			if (err != nil) != tt.err {
+				t.Fatalf("expected match error %t, got %v", tt.err, err)
+			}
+			if matches != tt.matches {
+				t.Errorf("expected matches %t, got %t", tt.matches, matches)
+			}
+		})
+	}
+}
+
+func TestGetNextPageIndexPreemption(t *testing.T) {
+	config := &DashboardConfig{
+		Pages: []PageConfig{
+			{Path: "pages/a.gohtml"},
+			{Path: "pages/b.gohtml", Priority: 5},
+			{Path: "pages/c.gohtml"},
+			{Path: "pages/d.gohtml", Priority: 5},
+			{Path: "pages/e.gohtml", Priority: 10},
+		},
+	}
+	// page e has the highest priority but is inactive, so b & d preempt a & c
+	active := []bool{true, true, true, true, false}
+
+	s := New(defaultConfig(), "test", "go", nil)
+	for action, expected := range map[Action]int{
+		ActionRefresh:   3,
+		ActionNextPage:  3,
+		ActionPrevPage:  1,
+		ActionFirstPage: 1,
+		ActionLastPage:  3,
+	} {
+		t.Run(string(action), func(t *testing.T) {
+			pageIndex, err := s.getNextPageIndex("default", config, active, 2, action, "")
+			if err != nil {
+				t.Fatalf("failed to get next page index: %s", err)
+			}
+			if pageIndex != expected {
+				t.Errorf("expected page %d, got %d", expected, pageIndex)
+			}
+		})
+	}
+}
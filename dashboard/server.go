@@ -2,6 +2,7 @@ package dashboard
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"image/png"
@@ -9,73 +10,445 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/chromedp/chromedp"
 
 	"github.com/topi314/esphome-dashboard/dashboard/homeassistant"
+	"github.com/topi314/esphome-dashboard/dashboard/mqtt"
 )
 
+// listenerShutdownTimeout is how long requests on replaced listeners are drained after a config reload.
+const listenerShutdownTimeout = 30 * time.Second
+
 func New(cfg Config, version string, goVersion string, templates fs.FS) *Server {
+	m := newServerMetrics()
 	s := &Server{
-		cfg:       cfg,
 		version:   version,
 		goVersion: goVersion,
 		templates: templates,
 		pngEncoder: &png.Encoder{
 			CompressionLevel: png.BestCompression,
 		},
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		cache:         newCache(m.cacheRequests),
+		metrics:       m,
+		devices:       newDeviceStore(cfg.StateFile),
+		internalToken: newInternalToken(),
+		stopped:       make(chan struct{}),
+		serveErrs:     make(chan error, 1),
 	}
+	s.cfg.Store(&cfg)
+	s.homeAssistant.Store(s.newHomeAssistantClient(cfg.HomeAssistant))
+	s.mqtt.Store(newMQTTClient(cfg.MQTT))
+	s.handler = s.Routes()
+
+	return s
+}
 
-	if cfg.HomeAssistant != nil {
-		s.homeAssistant = homeassistant.New(cfg.HomeAssistant.URL(), cfg.HomeAssistant.Token)
+func (s *Server) newHomeAssistantClient(cfg *HomeAssistantConfig) *homeassistant.Client {
+	if cfg == nil {
+		return nil
 	}
 
-	s.server = &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", cfg.ListenAddr, cfg.ListenPort),
-		Handler: s.Routes(),
+	client := homeassistant.New(cfg.URL(), cfg.Token)
+	client.OnRequest(s.metrics.observeHomeAssistantRequest)
+	return client
+}
+
+func newMQTTClient(cfg *MQTTConfig) *mqtt.Client {
+	if cfg == nil {
+		return nil
 	}
 
-	return s
+	topics := make([]mqtt.Topic, 0, len(cfg.Topics))
+	for _, topic := range cfg.Topics {
+		topics = append(topics, mqtt.Topic{
+			Name:  topic.Name,
+			Topic: topic.Topic,
+			QoS:   topic.QoS,
+		})
+	}
+	return mqtt.New(cfg.Broker, cfg.ClientID, cfg.Username, cfg.Password, topics)
 }
 
 type Server struct {
-	cfg           Config
+	cfg           atomic.Pointer[Config]
 	version       string
 	goVersion     string
 	templates     fs.FS
-	server        *http.Server
+	handler       http.Handler
 	pngEncoder    *png.Encoder
-	homeAssistant *homeassistant.Client
+	homeAssistant atomic.Pointer[homeassistant.Client]
+	mqtt          atomic.Pointer[mqtt.Client]
+	httpClient    *http.Client
+	cache         *cache
+	devices       *deviceStore
+	internalToken string
+	metrics       *serverMetrics
+	registry      dashboardRegistry
+
+	mu         sync.Mutex
+	reloadMu   sync.Mutex
+	chromeCtx  context.Context
+	stopChrome func()
+	renderAddr string
+	servers    []*http.Server
+	stopOnce   sync.Once
+	stopped    chan struct{}
+	serveErrs  chan error
 }
 
-func (s *Server) Start() {
-	if s.homeAssistant != nil {
-		status, err := s.homeAssistant.Test(context.Background())
-		if err != nil {
-			slog.Error("failed to connect to home assistant", slog.Any("err", err))
-		} else {
-			slog.Info("connected to home assistant", slog.String("status", status))
-		}
+// config returns the currently active config.
+func (s *Server) config() *Config {
+	return s.cfg.Load()
+}
+
+// homeAssistantClient returns the currently active Home Assistant client or nil if Home Assistant is not configured.
+func (s *Server) homeAssistantClient() *homeassistant.Client {
+	return s.homeAssistant.Load()
+}
+
+// mqttClient returns the currently active MQTT client or nil if MQTT is not configured.
+func (s *Server) mqttClient() *mqtt.Client {
+	return s.mqtt.Load()
+}
+
+// Start starts all dependencies & listeners and blocks until the server is stopped.
+// Errors during startup or while serving are returned, stopping the server via Stop returns nil.
+func (s *Server) Start() error {
+	if err := s.devices.load(); err != nil {
+		slog.Error("failed to load device state", slog.Any("err", err))
+	}
+
+	s.logValidationIssues()
+
+	// dev mode reads dashboards from disk on every request to pick up template changes immediately
+	if !s.config().Dev {
+		s.reloadDashboards()
+		go s.watchDashboards()
+	}
+
+	if homeAssistant := s.homeAssistantClient(); homeAssistant != nil {
+		testHomeAssistant(homeAssistant)
 	} else {
 		slog.Info("home assistant not configured, skipping connection test")
 	}
 
-	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), append(chromedp.DefaultExecAllocatorOptions[:], chromedp.NoSandbox)...)
-	defer allocCancel()
+	if mqttClient := s.mqttClient(); mqttClient != nil {
+		connectMQTT(mqttClient)
+	}
+
+	chromeCtx, err := s.startChrome()
+	if err != nil {
+		return err
+	}
 
+	s.reloadMu.Lock()
+	select {
+	case <-s.stopped:
+		// stopped while chrome was starting
+		s.reloadMu.Unlock()
+		s.closeChrome()
+		return nil
+	default:
+	}
+	s.mu.Lock()
+	s.chromeCtx = chromeCtx
+	s.mu.Unlock()
+	err = s.listen(*s.config())
+	s.reloadMu.Unlock()
+	if err != nil {
+		s.closeChrome()
+		return err
+	}
+
+	select {
+	case err = <-s.serveErrs:
+		return err
+	case <-s.stopped:
+		return nil
+	}
+}
+
+// startChrome starts the headless chrome used to render pages, it is stopped by closeChrome.
+func (s *Server) startChrome() (context.Context, error) {
+	allocOpts := append(chromedp.DefaultExecAllocatorOptions[:], chromedp.NoSandbox)
+	if s.config().TLS != nil {
+		// self-signed certificates are not trusted by chrome
+		allocOpts = append(allocOpts, chromedp.IgnoreCertErrors)
+	}
+
+	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), allocOpts...)
 	chromeCtx, chromeCancel := chromedp.NewContext(allocCtx)
-	defer chromeCancel()
+
+	s.mu.Lock()
+	s.stopChrome = func() {
+		// chromeCancel waits for the browser to exit, allocCancel cleans up the allocator afterward
+		chromeCancel()
+		allocCancel()
+	}
+	s.mu.Unlock()
+
 	if err := chromedp.Run(chromeCtx, chromedp.Navigate("about:blank")); err != nil {
-		slog.Error("failed to start chrome", slog.Any("err", err))
+		s.closeChrome()
+		return nil, fmt.Errorf("failed to start chrome: %w", err)
+	}
+
+	return chromeCtx, nil
+}
+
+func testHomeAssistant(client *homeassistant.Client) {
+	status, err := client.Test(context.Background())
+	if err != nil {
+		slog.Error("failed to connect to home assistant", slog.Any("err", err))
 		return
 	}
+	slog.Info("connected to home assistant", slog.String("status", status))
+}
 
-	s.server.BaseContext = func(listener net.Listener) context.Context {
-		return chromeCtx
+func connectMQTT(client *mqtt.Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		slog.Error("failed to connect to mqtt broker, retrying in background", slog.Any("err", err))
 	}
+}
 
-	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		slog.Error("server error", slog.Any("err", err))
-		return
+// listen binds the listeners of the given config and replaces the currently running listeners.
+// The current listeners are kept if any of the new listeners can't be bound.
+func (s *Server) listen(cfg Config) error {
+	var tlsConfig *tls.Config
+	if cfg.TLS != nil {
+		certs, err := newCertReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.SelfSigned, cfg.TLS.Hosts)
+		if err != nil {
+			return fmt.Errorf("failed to load certificate: %w", err)
+		}
+		tlsConfig = &tls.Config{
+			GetCertificate: certs.GetCertificate,
+		}
+	}
+
+	addr := fmt.Sprintf("%s:%d", cfg.ListenAddr, cfg.ListenPort)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	var httpLn net.Listener
+	if cfg.TLS != nil && cfg.TLS.HTTPPort != 0 {
+		// plain HTTP listener for devices which can't do TLS
+		httpAddr := fmt.Sprintf("%s:%d", cfg.ListenAddr, cfg.TLS.HTTPPort)
+		if httpLn, err = net.Listen("tcp", httpAddr); err != nil {
+			_ = ln.Close()
+			return fmt.Errorf("failed to listen on %s: %w", httpAddr, err)
+		}
+	}
+
+	server := s.newHTTPServer()
+	servers := []*http.Server{server}
+	if tlsConfig != nil {
+		server.TLSConfig = tlsConfig
+		go s.serve(func() error {
+			return server.ServeTLS(ln, "", "")
+		})
+	} else {
+		go s.serve(func() error {
+			return server.Serve(ln)
+		})
+	}
+
+	if httpLn != nil {
+		httpServer := s.newHTTPServer()
+		servers = append(servers, httpServer)
+		go s.serve(func() error {
+			return httpServer.Serve(httpLn)
+		})
+		slog.Info("listening", slog.String("addr", httpLn.Addr().String()), slog.Bool("tls", false))
+	}
+	slog.Info("listening", slog.String("addr", ln.Addr().String()), slog.Bool("tls", tlsConfig != nil))
+
+	s.mu.Lock()
+	oldServers := s.servers
+	s.servers = servers
+	s.mu.Unlock()
+
+	if len(oldServers) > 0 {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), listenerShutdownTimeout)
+			defer cancel()
+			if err := shutdownServers(ctx, oldServers); err != nil {
+				slog.Error("failed to shutdown old listeners", slog.Any("err", err))
+			}
+		}()
+	}
+
+	return nil
+}
+
+func (s *Server) newHTTPServer() *http.Server {
+	return &http.Server{
+		Handler: s.handler,
+		BaseContext: func(listener net.Listener) context.Context {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return s.chromeCtx
+		},
+	}
+}
+
+func (s *Server) serve(serve func() error) {
+	if err := serve(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		select {
+		case s.serveErrs <- fmt.Errorf("failed to serve: %w", err):
+		default:
+		}
+	}
+}
+
+func shutdownServers(ctx context.Context, servers []*http.Server) error {
+	var errs []error
+	for _, server := range servers {
+		if err := server.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to shutdown server: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Stop gracefully stops the server.
+// In-flight requests are drained until ctx is done, afterward running renders are canceled.
+// Chrome, the Home Assistant client & the MQTT connection are closed afterward.
+func (s *Server) Stop(ctx context.Context) error {
+	s.stopOnce.Do(func() {
+		close(s.stopped)
+	})
+
+	// reloadMu makes sure no listeners are started concurrently
+	s.reloadMu.Lock()
+	s.mu.Lock()
+	servers := s.servers
+	s.servers = nil
+	s.mu.Unlock()
+	s.reloadMu.Unlock()
+
+	err := shutdownServers(ctx, servers)
+
+	// canceling chrome also cancels all requests & renders which did not finish in time
+	s.closeChrome()
+
+	if homeAssistant := s.homeAssistantClient(); homeAssistant != nil {
+		homeAssistant.Close()
+	}
+	if mqttClient := s.mqttClient(); mqttClient != nil {
+		mqttClient.Close()
+	}
+	s.httpClient.CloseIdleConnections()
+
+	return err
+}
+
+func (s *Server) closeChrome() {
+	s.mu.Lock()
+	stopChrome := s.stopChrome
+	s.stopChrome = nil
+	s.mu.Unlock()
+
+	if stopChrome != nil {
+		stopChrome()
+	}
+}
+
+// Reload applies the given config to the running server.
+// Listeners are replaced if the listen address, port or TLS config changed, the old config stays active if they can't be bound.
+// The Home Assistant & MQTT clients are recreated if their config changed.
+func (s *Server) Reload(cfg Config) error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	select {
+	case <-s.stopped:
+		return errors.New("server is stopped")
+	default:
+	}
+
+	oldCfg := *s.config()
+	changes := configDiff(oldCfg, cfg)
+	if len(changes) == 0 {
+		slog.Info("config unchanged")
+		return nil
+	}
+	slog.Info("reloading config", slog.Any("changes", changes))
+
+	s.mu.Lock()
+	started := s.chromeCtx != nil
+	s.mu.Unlock()
+
+	if started && (oldCfg.ListenAddr != cfg.ListenAddr || oldCfg.ListenPort != cfg.ListenPort || !reflect.DeepEqual(oldCfg.TLS, cfg.TLS)) {
+		if err := s.listen(cfg); err != nil {
+			return fmt.Errorf("failed to apply listener config: %w", err)
+		}
+	}
+	s.cfg.Store(&cfg)
+
+	if !reflect.DeepEqual(oldCfg.HomeAssistant, cfg.HomeAssistant) {
+		homeAssistant := s.newHomeAssistantClient(cfg.HomeAssistant)
+		if oldClient := s.homeAssistant.Swap(homeAssistant); oldClient != nil {
+			oldClient.Close()
+		}
+		if homeAssistant != nil {
+			go testHomeAssistant(homeAssistant)
+		}
+	}
+
+	if !reflect.DeepEqual(oldCfg.MQTT, cfg.MQTT) {
+		mqttClient := newMQTTClient(cfg.MQTT)
+		if mqttClient != nil {
+			connectMQTT(mqttClient)
+		}
+		if oldClient := s.mqtt.Swap(mqttClient); oldClient != nil {
+			oldClient.Close()
+		}
+	}
+
+	if oldCfg.Dev != cfg.Dev {
+		slog.Warn("changing dev requires a restart")
+	}
+	if oldCfg.StateFile != cfg.StateFile {
+		slog.Warn("changing state_file requires a restart")
+	}
+	if oldCfg.TLS == nil && cfg.TLS != nil && cfg.TLS.HTTPPort == 0 {
+		slog.Warn("enabling tls without http_port requires a restart for chrome to accept self-signed certificates")
+	}
+
+	return nil
+}
+
+// renderURL returns the URL chrome uses to load the HTML of a page.
+func (s *Server) renderURL(dashboard string, pageIndex int) string {
+	return fmt.Sprintf("%s/dashboards/%s/pages/%d?html=1", s.renderBaseURL(), dashboard, pageIndex)
+}
+
+// renderBaseURL returns the URL of the local listener chrome loads pages from.
+func (s *Server) renderBaseURL() string {
+	s.mu.Lock()
+	renderAddr := s.renderAddr
+	s.mu.Unlock()
+	if renderAddr != "" {
+		return "http://" + renderAddr
+	}
+
+	cfg := s.config()
+	if cfg.TLS == nil {
+		return fmt.Sprintf("http://localhost:%d", cfg.ListenPort)
+	}
+	if cfg.TLS.HTTPPort != 0 {
+		return fmt.Sprintf("http://localhost:%d", cfg.TLS.HTTPPort)
 	}
+	return fmt.Sprintf("https://localhost:%d", cfg.ListenPort)
 }
@@ -0,0 +1,162 @@
+package dashboard
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// minSleepDuration prevents devices from waking up in a tight loop if a boundary is right ahead.
+const minSleepDuration = 30 * time.Second
+
+type SleepReason string
+
+const (
+	SleepReasonPageTiming    SleepReason = "page_timing"
+	SleepReasonSchedule      SleepReason = "schedule"
+	SleepReasonCalendarEvent SleepReason = "calendar_event"
+	SleepReasonMidnight      SleepReason = "midnight"
+	SleepReasonQuietHours    SleepReason = "quiet_hours"
+	SleepReasonShow          SleepReason = "show"
+)
+
+type Sleep struct {
+	Duration time.Duration
+	WakeAt   time.Time
+	Reason   SleepReason
+}
+
+// getSleep calculates how long a device showing the given page can sleep until the content changes in a meaningful way.
+func (s *Server) getSleep(ctx context.Context, dashboard string, config *DashboardConfig, pageIndex int, now time.Time) Sleep {
+	year, month, day := now.Date()
+	sleep := Sleep{
+		WakeAt: time.Date(year, month, day+1, 0, 0, 0, 0, now.Location()),
+		Reason: SleepReasonMidnight,
+	}
+	candidate := func(t time.Time, reason SleepReason) {
+		if t.After(now) && t.Before(sleep.WakeAt) {
+			sleep.WakeAt = t
+			sleep.Reason = reason
+		}
+	}
+
+	timing := s.getPageTiming(dashboard, config, pageIndex)
+	if timing.RefreshInterval > 0 {
+		candidate(now.Add(timing.RefreshInterval), SleepReasonPageTiming)
+	}
+	if timing.DwellTime > 0 {
+		candidate(now.Add(timing.DwellTime), SleepReasonPageTiming)
+	}
+
+	for _, page := range config.Pages {
+		for _, schedule := range page.Schedules {
+			for _, clock := range []string{schedule.From, schedule.To} {
+				if t, ok := nextClockTime(clock, now); ok {
+					candidate(t, SleepReasonSchedule)
+				}
+			}
+		}
+	}
+
+	if t, ok := s.nextCalendarBoundary(ctx, config.HomeAssistant.Calendars, now, sleep.WakeAt); ok {
+		candidate(t, SleepReasonCalendarEvent)
+	}
+
+	if config.QuietHours != nil {
+		if quietEnd, ok := quietHoursEnd(*config.QuietHours, now, sleep.WakeAt); ok {
+			sleep.WakeAt = quietEnd
+			sleep.Reason = SleepReasonQuietHours
+		}
+	}
+
+	sleep.Duration = sleep.WakeAt.Sub(now)
+	if sleep.Duration < minSleepDuration {
+		sleep.Duration = minSleepDuration
+		sleep.WakeAt = now.Add(minSleepDuration)
+	}
+	return sleep
+}
+
+// capSleepAtShow wakes the device when its active show command expires so it returns to the page rotation.
+func capSleepAtShow(sleep Sleep, show *ShowCommand, now time.Time) Sleep {
+	if show == nil || !show.IsActive() || !show.Until.Before(sleep.WakeAt) {
+		return sleep
+	}
+	sleep.WakeAt = show.Until
+	sleep.Duration = show.Until.Sub(now)
+	sleep.Reason = SleepReasonShow
+	return sleep
+}
+
+// nextClockTime returns the next occurrence of a time of day after now.
+func nextClockTime(clock string, now time.Time) (time.Time, bool) {
+	if clock == "" {
+		return time.Time{}, false
+	}
+	d, err := parseClock(clock, 0)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	year, month, day := now.Date()
+	t := time.Date(year, month, day, 0, 0, 0, 0, now.Location()).Add(d)
+	if !t.After(now) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, true
+}
+
+// nextCalendarBoundary returns the next start or end of a calendar event between now and until.
+func (s *Server) nextCalendarBoundary(ctx context.Context, calendars []CalendarConfig, now time.Time, until time.Time) (time.Time, bool) {
+	homeAssistant := s.homeAssistantClient()
+	if homeAssistant == nil || len(calendars) == 0 {
+		return time.Time{}, false
+	}
+
+	var (
+		next  time.Time
+		found bool
+	)
+	for _, calendar := range calendars {
+		for _, id := range calendar.IDs {
+			events, err := homeAssistant.GetCalendar(ctx, id, now, until)
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to get calendar for sleep calculation", slog.String("calendar", calendar.Name), slog.String("entity_id", id), slog.Any("err", err))
+				continue
+			}
+
+			for _, event := range events {
+				for _, t := range []time.Time{event.Start.Time(), event.End.Time()} {
+					if t.After(now) && (!found || t.Before(next)) {
+						next = t
+						found = true
+					}
+				}
+			}
+		}
+	}
+	return next, found
+}
+
+// quietHoursEnd returns the end of the quiet hours if now or wakeAt is within the quiet hours.
+func quietHoursEnd(quietHours ScheduleConfig, now time.Time, wakeAt time.Time) (time.Time, bool) {
+	for _, t := range []time.Time{now, wakeAt} {
+		quiet, err := quietHours.matches(t)
+		if err != nil {
+			slog.Error("invalid quiet hours", slog.Any("err", err))
+			return time.Time{}, false
+		}
+		if !quiet {
+			continue
+		}
+
+		end, ok := nextClockTime(quietHours.To, t)
+		if !ok {
+			// quiet hours without an end last until midnight
+			year, month, day := t.Date()
+			end = time.Date(year, month, day+1, 0, 0, 0, 0, t.Location())
+		}
+		return end, true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,186 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxHTTPSourceSize is the maximum size of a http source response body.
+const maxHTTPSourceSize = 10 << 20
+
+func (s *Server) fetchHTTPSources(ctx context.Context, sources []HTTPSourceConfig) map[string]any {
+	values := make(map[string]any)
+	for _, source := range sources {
+		value, err := s.fetchHTTPSource(ctx, source)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to fetch http source", slog.String("source", source.Name), slog.String("url", source.URL), slog.Any("err", err))
+			continue
+		}
+
+		if source.Extract != "" {
+			value, err = extractPath(value, source.Extract)
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to extract http source value", slog.String("source", source.Name), slog.String("extract", source.Extract), slog.Any("err", err))
+				continue
+			}
+		}
+		values[source.Name] = value
+	}
+
+	return values
+}
+
+func (s *Server) fetchHTTPSource(ctx context.Context, source HTTPSourceConfig) (any, error) {
+	method := source.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	cacheKey := httpSourceCacheKey(method, source)
+	if value, ok := s.cache.get(cacheKey); ok {
+		return value, nil
+	}
+
+	var body io.Reader
+	if source.Body != "" {
+		body = strings.NewReader(source.Body)
+	}
+
+	rq, err := http.NewRequestWithContext(ctx, method, source.URL, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range source.Headers {
+		rq.Header.Set(k, v)
+	}
+
+	rs, err := s.httpClient.Do(rq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer rs.Body.Close()
+
+	if rs.StatusCode < 200 || rs.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status: %s", rs.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(rs.Body, maxHTTPSourceSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if len(data) > maxHTTPSourceSize {
+		return nil, fmt.Errorf("response exceeds %d bytes", maxHTTPSourceSize)
+	}
+
+	var value any
+	mediaType, _, _ := mime.ParseMediaType(rs.Header.Get("Content-Type"))
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") || source.Extract != "" {
+		if err = json.Unmarshal(data, &value); err != nil {
+			return nil, fmt.Errorf("failed to decode json response: %w", err)
+		}
+	} else {
+		value = string(data)
+	}
+
+	s.cache.set(cacheKey, value, source.CacheTTL)
+	return value, nil
+}
+
+// httpSourceCacheKey returns the cache key of a source request.
+// The headers are part of the key since they can change the response, e.g. for different credentials or content types.
+func httpSourceCacheKey(method string, source HTTPSourceConfig) string {
+	headers := make([]string, 0, len(source.Headers))
+	for k, v := range source.Headers {
+		headers = append(headers, http.CanonicalHeaderKey(k)+": "+v)
+	}
+	slices.Sort(headers)
+
+	return "http_source:" + method + " " + source.URL + "\n" + strings.Join(headers, "\n") + "\n\n" + source.Body
+}
+
+// extractPath extracts a value from decoded JSON using a jq-like path (e.g. `.data.items[0].name` or `.items[].name`).
+func extractPath(value any, path string) (any, error) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), ".")
+	if path == "" {
+		return value, nil
+	}
+
+	var segment string
+	var rest string
+	switch {
+	case strings.HasPrefix(path, "["):
+		end := strings.Index(path, "]")
+		if end == -1 {
+			return nil, fmt.Errorf("missing closing bracket in path: %s", path)
+		}
+		segment = path[:end+1]
+		rest = path[end+1:]
+	default:
+		end := strings.IndexAny(path, ".[")
+		if end == -1 {
+			end = len(path)
+		}
+		segment = path[:end]
+		rest = path[end:]
+	}
+
+	if strings.HasPrefix(segment, "[") {
+		index := strings.TrimSpace(segment[1 : len(segment)-1])
+		list, ok := value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("can't index %s with %s", typeName(value), segment)
+		}
+
+		// an empty index or * maps the rest of the path over all elements
+		if index == "" || index == "*" {
+			values := make([]any, 0, len(list))
+			for _, v := range list {
+				extracted, err := extractPath(v, rest)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, extracted)
+			}
+			return values, nil
+		}
+
+		i, err := strconv.Atoi(index)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index %s: %w", segment, err)
+		}
+		if i < 0 {
+			i += len(list)
+		}
+		if i < 0 || i >= len(list) {
+			return nil, fmt.Errorf("index %s out of range", segment)
+		}
+		return extractPath(list[i], rest)
+	}
+
+	m, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("can't get key %s of %s", segment, typeName(value))
+	}
+	v, ok := m[segment]
+	if !ok {
+		return nil, errors.New("key not found: " + segment)
+	}
+	return extractPath(v, rest)
+}
+
+func typeName(v any) string {
+	if v == nil {
+		return "null"
+	}
+	return reflect.TypeOf(v).String()
+}
@@ -0,0 +1,84 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractPath(t *testing.T) {
+	var value any
+	if err := json.Unmarshal([]byte(`{
+		"data": {
+			"items": [
+				{"name": "first", "tags": ["a", "b"]},
+				{"name": "second", "tags": []},
+				{"name": "third", "meta": {"count": 3}}
+			],
+			"total": 3
+		},
+		"empty": null
+	}`), &value); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected any
+		err      string
+	}{
+		{name: "root", path: ".", expected: value},
+		{name: "empty", path: "", expected: value},
+		{name: "nested key", path: ".data.total", expected: float64(3)},
+		{name: "without leading dot", path: "data.total", expected: float64(3)},
+		{name: "array index", path: ".data.items[1].name", expected: "second"},
+		{name: "negative index", path: ".data.items[-1].meta.count", expected: float64(3)},
+		{name: "nested index", path: ".data.items[0].tags[1]", expected: "b"},
+		{name: "all elements", path: ".data.items[].name", expected: []any{"first", "second", "third"}},
+		{name: "wildcard", path: ".data.items[*].name", expected: []any{"first", "second", "third"}},
+		{name: "null value", path: ".empty", expected: nil},
+		{name: "missing key", path: ".data.missing", err: "key not found: missing"},
+		{name: "missing key in elements", path: ".data.items[].meta", err: "key not found: meta"},
+		{name: "index out of range", path: ".data.items[3]", err: "index [3] out of range"},
+		{name: "invalid index", path: ".data.items[x]", err: "invalid index [x]"},
+		{name: "missing bracket", path: ".data.items[0", err: "missing closing bracket"},
+		{name: "index of object", path: ".data[0]", err: "can't index map[string]interface {} with [0]"},
+		{name: "key of array", path: ".data.items.name", err: "can't get key name of []interface {}"},
+		{name: "key of null", path: ".empty.name", err: "can't get key name of null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extracted, err := extractPath(value, tt.path)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to extract path: %s", err)
+			}
+			if !reflect.DeepEqual(extracted, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, extracted)
+			}
+		})
+	}
+}
+
+func TestFetchHTTPSourceLimitsBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", maxHTTPSourceSize+1)))
+	}))
+	defer srv.Close()
+
+	s := New(defaultConfig(), "test", "go", nil)
+	if _, err := s.fetchHTTPSource(context.Background(), HTTPSourceConfig{Name: "large", URL: srv.URL}); err == nil || !strings.Contains(err.Error(), "response exceeds") {
+		t.Fatalf("expected size error, got %v", err)
+	}
+}
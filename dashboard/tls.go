@@ -0,0 +1,182 @@
+package dashboard
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// selfSignedValidity is how long generated self-signed certificates are valid.
+const selfSignedValidity = 365 * 24 * time.Hour
+
+// newCertReloader returns a certReloader for the given files.
+// If selfSigned is true and the files don't exist, a self-signed certificate for the given hosts is generated and persisted.
+func newCertReloader(certFile string, keyFile string, selfSigned bool, hosts []string) (*certReloader, error) {
+	if selfSigned {
+		if err := ensureSelfSignedCert(certFile, keyFile, hosts); err != nil {
+			return nil, err
+		}
+	}
+
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// certReloader loads a certificate from disk and reloads it as soon as the certificate or key file changes.
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func (r *certReloader) modTimes() (time.Time, error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat cert file: %w", err)
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to stat key file: %w", err)
+	}
+
+	if keyInfo.ModTime().After(certInfo.ModTime()) {
+		return keyInfo.ModTime(), nil
+	}
+	return certInfo.ModTime(), nil
+}
+
+func (r *certReloader) reload() error {
+	modTime, err := r.modTimes()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	r.cert = &cert
+	r.modTime = modTime
+	return nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+// The previous certificate is kept if the changed files can't be loaded, e.g. while they are being written.
+func (r *certReloader) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if modTime, err := r.modTimes(); err == nil && !modTime.Equal(r.modTime) {
+		if err = r.reload(); err != nil {
+			slog.Error("failed to reload certificate", slog.Any("err", err))
+		} else {
+			slog.Info("reloaded certificate", slog.String("cert_file", r.certFile))
+		}
+	}
+
+	return r.cert, nil
+}
+
+// ensureSelfSignedCert generates a self-signed certificate & key if they don't exist yet.
+func ensureSelfSignedCert(certFile string, keyFile string, hosts []string) error {
+	_, certErr := os.Stat(certFile)
+	_, keyErr := os.Stat(keyFile)
+	if certErr == nil && keyErr == nil {
+		return nil
+	}
+	if !errors.Is(certErr, fs.ErrNotExist) && certErr != nil {
+		return fmt.Errorf("failed to stat cert file: %w", certErr)
+	}
+	if !errors.Is(keyErr, fs.ErrNotExist) && keyErr != nil {
+		return fmt.Errorf("failed to stat key file: %w", keyErr)
+	}
+
+	slog.Info("generating self-signed certificate", slog.String("cert_file", certFile), slog.String("key_file", keyFile), slog.Any("hosts", hosts))
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	now := time.Now()
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			Organization: []string{"ESPHome Dashboard"},
+			CommonName:   "esphome-dashboard",
+		},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, host := range append([]string{"localhost", "127.0.0.1"}, hosts...) {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to marshal key: %w", err)
+	}
+
+	if err = writePEM(keyFile, "PRIVATE KEY", keyDER, 0600); err != nil {
+		return err
+	}
+	// the cert is written last so a partially generated pair is regenerated on the next start
+	if err = writePEM(certFile, "CERTIFICATE", der, 0644); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func writePEM(path string, blockType string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data}), perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,660 @@
+package dashboard
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/topi314/esphome-dashboard/dashboard/homeassistant"
+)
+
+// ValidationIssue is a problem found in a config file.
+type ValidationIssue struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Key     string `json:"key,omitempty"`
+	Message string `json:"message"`
+}
+
+func (i ValidationIssue) String() string {
+	location := i.File
+	if i.Line > 0 {
+		location += ":" + strconv.Itoa(i.Line)
+	}
+	if i.Key != "" {
+		return fmt.Sprintf("%s: %s: %s", location, i.Key, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", location, i.Message)
+}
+
+type ValidationResponse struct {
+	Valid  bool              `json:"valid"`
+	Issues []ValidationIssue `json:"issues"`
+}
+
+// validator collects issues of a single config file and resolves their line numbers from the raw file.
+type validator struct {
+	file   string
+	data   []byte
+	issues []ValidationIssue
+}
+
+// addf adds an issue for the given dotted key path like "pages.1.path".
+// The line is looked up by the key and, if not empty, the value of the key.
+func (v *validator) addf(key string, value string, format string, args ...any) {
+	v.issues = append(v.issues, ValidationIssue{
+		File:    v.file,
+		Line:    v.line(key, value),
+		Key:     key,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// decodeErrPattern matches errors like `toml: line 2 (last key "height"): incompatible types: ...`.
+var decodeErrPattern = regexp.MustCompile(`^toml: line (\d+) \(last key "(.*?)"\): (.*)$`)
+
+// yamlErrPattern matches errors like `yaml: line 2: mapping values are not allowed in this context`.
+var yamlErrPattern = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// addErr adds an issue for an error, using the position of TOML parse errors.
+func (v *validator) addErr(err error) {
+	var durationErr *IntegerDurationError
+	if errors.As(err, &durationErr) {
+		for i, key := range durationErr.Keys {
+			v.addf(key, strconv.FormatInt(durationErr.Values[i], 10), "duration must be a string like \"10m\", integers are not supported")
+		}
+		return
+	}
+
+	issue := ValidationIssue{
+		File:    v.file,
+		Message: err.Error(),
+	}
+	var parseErr toml.ParseError
+	if errors.As(err, &parseErr) {
+		issue.Line = parseErr.Position.Line
+		issue.Key = parseErr.LastKey
+		if parseErr.Message != "" {
+			issue.Message = parseErr.Message
+		}
+	} else if match := decodeErrPattern.FindStringSubmatch(err.Error()); match != nil {
+		// type errors of the decoder only contain the position in the message
+		issue.Key = match[2]
+		issue.Message = match[3]
+		if strings.EqualFold(filepath.Ext(v.file), ".toml") {
+			issue.Line, _ = strconv.Atoi(match[1])
+		} else {
+			// YAML & JSON are converted to TOML before decoding, so the line refers to the converted config
+			issue.Line = v.line(issue.Key, "")
+		}
+	} else if match := yamlErrPattern.FindStringSubmatch(err.Error()); match != nil {
+		issue.Line, _ = strconv.Atoi(match[1])
+		issue.Message = match[2]
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		issue.Line = bytes.Count(v.data[:min(int(syntaxErr.Offset), len(v.data))], []byte("\n")) + 1
+	}
+	v.issues = append(v.issues, issue)
+}
+
+// decode expands environment variables in the file and decodes it into cfg.
+// Keys which don't exist in cfg are reported as issues.
+func (v *validator) decode(cfg any) bool {
+	data, err := expandEnv(v.data)
+	if err != nil {
+		v.addErr(err)
+		return false
+	}
+
+	md, err := decodeConfig(v.file, data, cfg)
+	if err != nil {
+		v.addErr(err)
+		return false
+	}
+
+	// keys of tables with a custom unmarshaler are always undecoded, so they are checked against the struct tags instead
+	var unknown []string
+	for _, key := range md.Undecoded() {
+		if knownKey(reflect.TypeOf(cfg), key) {
+			continue
+		}
+		name := key.String()
+		if slices.ContainsFunc(unknown, func(parent string) bool { return strings.HasPrefix(name, parent+".") }) {
+			continue
+		}
+		unknown = append(unknown, name)
+		v.addf(name, "", "unknown key")
+	}
+	return true
+}
+
+// line returns the line of the given dotted key path or 0 if it can't be found.
+// Keys are matched in TOML (key = value), YAML (key: value) & JSON ("key": value) syntax.
+// Array indexes in the path select the matching [[table]] header if the array is written as array of tables.
+func (v *validator) line(key string, value string) int {
+	var (
+		offset int
+		table  []string
+		name   string
+	)
+	segments := strings.Split(key, ".")
+	for i, segment := range segments {
+		if index, err := strconv.Atoi(segment); err == nil {
+			if start := tableHeaderIndex(v.data[offset:], strings.Join(table, "."), index); start >= 0 {
+				offset += start
+			}
+			continue
+		}
+		if i == len(segments)-1 {
+			name = segment
+		} else {
+			table = append(table, segment)
+		}
+	}
+
+	if name == "" && len(table) > 0 {
+		// the path points to an array element, e.g. pages.1.schedules.0
+		name = table[len(table)-1]
+	}
+
+	var patterns []string
+	if name != "" {
+		if value != "" {
+			patterns = append(patterns, `(?:^|[\s{,])(["']?`+regexp.QuoteMeta(name)+`["']?)\s*[=:]\s*["']?`+regexp.QuoteMeta(value)+`["']?`)
+		}
+		patterns = append(patterns, `(?:^|[\s{,])(["']?`+regexp.QuoteMeta(name)+`["']?)\s*[=:]`)
+	}
+	if value != "" {
+		patterns = append(patterns, `(["']`+regexp.QuoteMeta(value)+`["'])`)
+	}
+	patterns = append(patterns, `(?m)^\s*(\[+\s*`+regexp.QuoteMeta(key)+`\s*\]+)`)
+
+	for _, pattern := range patterns {
+		if loc := regexp.MustCompile(pattern).FindSubmatchIndex(v.data[offset:]); loc != nil {
+			return bytes.Count(v.data[:offset+loc[2]], []byte("\n")) + 1
+		}
+	}
+	return 0
+}
+
+// tableHeaderIndex returns the offset of the n-th [[table]] header or -1.
+func tableHeaderIndex(data []byte, table string, n int) int {
+	matches := regexp.MustCompile(`(?m)^\s*\[\[\s*`+regexp.QuoteMeta(table)+`\s*\]\]`).FindAllIndex(data, n+1)
+	if len(matches) <= n {
+		return -1
+	}
+	return matches[n][0]
+}
+
+// knownKey returns whether the key path exists in the toml tags of the given type.
+func knownKey(t reflect.Type, key []string) bool {
+	for _, name := range key {
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Map, reflect.Interface:
+			return true
+		case reflect.Struct:
+			field, ok := tomlField(t, name)
+			if !ok {
+				return false
+			}
+			t = field.Type
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func tomlField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+		if tag == name || (tag == "" && strings.EqualFold(field.Name, name)) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// ValidateConfig validates the main config file and returns all found issues.
+func ValidateConfig(cfgPath string) []ValidationIssue {
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		return []ValidationIssue{{File: cfgPath, Message: err.Error()}}
+	}
+
+	v := &validator{file: cfgPath, data: data}
+	cfg := defaultConfig()
+	if !v.decode(&cfg) {
+		return v.issues
+	}
+	if err = applyEnvOverrides(&cfg, EnvPrefix); err != nil {
+		v.addErr(err)
+		return v.issues
+	}
+
+	if !slices.Contains([]LogFormat{LogFormatJSON, LogFormatText, LogFormatLogFMT}, cfg.Log.Format) {
+		v.addf("log.format", string(cfg.Log.Format), "unknown log format %q", cfg.Log.Format)
+	}
+	if cfg.ListenPort < 1 || cfg.ListenPort > 65535 {
+		v.addf("listen_port", strconv.Itoa(cfg.ListenPort), "invalid port %d", cfg.ListenPort)
+	}
+	if info, err := os.Stat(cfg.DashboardDir); err != nil {
+		v.addf("dashboard_dir", cfg.DashboardDir, "dashboard dir not found: %s", err)
+	} else if !info.IsDir() {
+		v.addf("dashboard_dir", cfg.DashboardDir, "dashboard dir is not a directory")
+	}
+	for i, proxy := range cfg.TrustedProxies {
+		if _, err = parseTrustedProxy(proxy); err != nil {
+			v.addf(fmt.Sprintf("trusted_proxies.%d", i), proxy, "invalid trusted proxy %q, must be an IP address or CIDR range", proxy)
+		}
+	}
+
+	if cfg.HomeAssistant != nil {
+		if cfg.HomeAssistant.Host == "" {
+			v.addf("home_assistant.host", "", "host is required")
+		}
+		if cfg.HomeAssistant.Port < 1 || cfg.HomeAssistant.Port > 65535 {
+			v.addf("home_assistant.port", strconv.Itoa(cfg.HomeAssistant.Port), "invalid port %d", cfg.HomeAssistant.Port)
+		}
+		if cfg.HomeAssistant.Token == "" {
+			if cfg.HomeAssistant.TokenFile == "" {
+				v.addf("home_assistant.token", "", "token or token_file is required")
+			} else if _, err = readSecretFile(cfg.HomeAssistant.TokenFile); err != nil {
+				v.addf("home_assistant.token_file", cfg.HomeAssistant.TokenFile, "failed to read token file: %s", err)
+			}
+		}
+	}
+
+	if cfg.MQTT != nil {
+		if cfg.MQTT.Broker == "" {
+			v.addf("mqtt.broker", "", "broker is required")
+		}
+		names := make(map[string]struct{}, len(cfg.MQTT.Topics))
+		for i, topic := range cfg.MQTT.Topics {
+			key := fmt.Sprintf("mqtt.topics.%d", i)
+			v.checkName(names, key, topic.Name)
+			if topic.Topic == "" {
+				v.addf(key+".topic", "", "topic is required")
+			}
+			if topic.QoS > 2 {
+				v.addf(key+".qos", strconv.Itoa(int(topic.QoS)), "invalid qos %d", topic.QoS)
+			}
+		}
+	}
+
+	if cfg.Auth != nil {
+		names := make(map[string]struct{}, len(cfg.Auth.Keys))
+		keys := make(map[string]struct{}, len(cfg.Auth.Keys))
+		for i, key := range cfg.Auth.Keys {
+			path := fmt.Sprintf("auth.keys.%d", i)
+			v.checkName(names, path, key.Name)
+			if key.Key == "" {
+				v.addf(path+".key", "", "key is required")
+			} else if _, ok := keys[key.Key]; ok {
+				v.addf(path+".key", "", "duplicate key")
+			}
+			keys[key.Key] = struct{}{}
+		}
+
+		usernames := make(map[string]struct{}, len(cfg.Auth.Users))
+		for i, user := range cfg.Auth.Users {
+			path := fmt.Sprintf("auth.users.%d", i)
+			if user.Username == "" {
+				v.addf(path+".username", "", "username is required")
+			} else if _, ok := usernames[user.Username]; ok {
+				v.addf(path+".username", user.Username, "duplicate username %q", user.Username)
+			}
+			usernames[user.Username] = struct{}{}
+			if user.Password == "" {
+				v.addf(path+".password", "", "password is required")
+			}
+		}
+	}
+
+	if cfg.TLS != nil {
+		if cfg.TLS.CertFile == "" {
+			v.addf("tls.cert_file", "", "cert_file is required")
+		}
+		if cfg.TLS.KeyFile == "" {
+			v.addf("tls.key_file", "", "key_file is required")
+		}
+		if !cfg.TLS.SelfSigned {
+			v.checkFile("tls.cert_file", cfg.TLS.CertFile)
+			v.checkFile("tls.key_file", cfg.TLS.KeyFile)
+		}
+		if cfg.TLS.HTTPPort < 0 || cfg.TLS.HTTPPort > 65535 {
+			v.addf("tls.http_port", strconv.Itoa(cfg.TLS.HTTPPort), "invalid port %d", cfg.TLS.HTTPPort)
+		} else if cfg.TLS.HTTPPort == cfg.ListenPort {
+			v.addf("tls.http_port", strconv.Itoa(cfg.TLS.HTTPPort), "http_port must differ from listen_port")
+		}
+	}
+
+	return v.issues
+}
+
+// checkName reports empty and duplicate names within the same list.
+func (v *validator) checkName(names map[string]struct{}, key string, name string) {
+	if name == "" {
+		v.addf(key+".name", "", "name is required")
+		return
+	}
+	if _, ok := names[name]; ok {
+		v.addf(key+".name", name, "duplicate name %q", name)
+	}
+	names[name] = struct{}{}
+}
+
+// checkFile reports if the file doesn't exist.
+func (v *validator) checkFile(key string, path string) {
+	if path == "" {
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		v.addf(key, path, "file not found: %s", path)
+	}
+}
+
+// checkNotNegative reports negative numbers or durations.
+func (v *validator) checkNotNegative(key string, value int64) {
+	if value < 0 {
+		v.addf(key, "-", "must not be negative")
+	}
+}
+
+// validateDashboard validates the config, files & templates of a dashboard and returns all found issues.
+func (s *Server) validateDashboard(dashboard string) []ValidationIssue {
+	dashboardDir := filepath.Join(s.config().DashboardDir, dashboard)
+	cfgPath, err := s.dashboardConfigFile(dashboard)
+	if err != nil {
+		return []ValidationIssue{{File: dashboardDir, Message: err.Error()}}
+	}
+
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		return []ValidationIssue{{File: cfgPath, Message: err.Error()}}
+	}
+
+	v := &validator{file: cfgPath, data: data}
+	var cfg DashboardConfig
+	if !v.decode(&cfg) {
+		return v.issues
+	}
+
+	if cfg.Width <= 0 {
+		v.addf("width", "", "width must be greater than 0")
+	}
+	if cfg.Height <= 0 {
+		v.addf("height", "", "height must be greater than 0")
+	}
+	v.checkNotNegative("refresh_interval", int64(cfg.RefreshInterval))
+	v.checkNotNegative("dwell_time", int64(cfg.DwellTime))
+	if cfg.QuietHours != nil {
+		if err = cfg.QuietHours.validate(); err != nil {
+			v.addf("quiet_hours", "", "%s", err)
+		}
+	}
+
+	if cfg.Base == "" {
+		v.addf("base", "", "base is required")
+	} else {
+		v.checkFile("base", filepath.Join(dashboardDir, cfg.Base))
+	}
+
+	if len(cfg.Pages) == 0 {
+		v.addf("pages", "", "at least one page is required")
+	}
+	pageNames := make(map[string]struct{}, len(cfg.Pages))
+	for i, pageConfig := range cfg.Pages {
+		key := "pages." + strconv.Itoa(i)
+		v.checkNotNegative(key+".refresh_interval", int64(pageConfig.RefreshInterval))
+		v.checkNotNegative(key+".dwell_time", int64(pageConfig.DwellTime))
+		for j, schedule := range pageConfig.Schedules {
+			if err = schedule.validate(); err != nil {
+				v.addf(fmt.Sprintf("%s.schedules.%d", key, j), "", "%s", err)
+			}
+		}
+		for j, condition := range pageConfig.Conditions {
+			if condition.Entity == "" {
+				v.addf(fmt.Sprintf("%s.conditions.%d.entity", key, j), "", "entity is required")
+			}
+		}
+
+		if pageConfig.Path == "" {
+			v.addf(key+".path", "", "path is required")
+			continue
+		}
+		page, err := s.loadPage(dashboard, i, pageConfig)
+		if err != nil {
+			v.addf(key+".path", pageConfig.Path, "failed to load page: %s", err)
+			continue
+		}
+		for _, name := range append([]string{page.Name}, page.Aliases...) {
+			if _, err = strconv.Atoi(name); err == nil {
+				v.addf(key, pageConfig.Path, "page name or alias %q can't be a number", name)
+			} else if _, ok := pageNames[name]; ok {
+				v.addf(key, pageConfig.Path, "duplicate page name or alias %q", name)
+			}
+			pageNames[name] = struct{}{}
+		}
+	}
+
+	names := make(map[string]struct{})
+	for i, entity := range cfg.HomeAssistant.Entities {
+		key := "home_assistant.entities." + strconv.Itoa(i)
+		v.checkName(names, key, entity.Name)
+		if entity.ID == "" {
+			v.addf(key+".id", "", "id is required")
+		}
+	}
+
+	clear(names)
+	for i, calendar := range cfg.HomeAssistant.Calendars {
+		key := "home_assistant.calendars." + strconv.Itoa(i)
+		v.checkName(names, key, calendar.Name)
+		if len(calendar.IDs) == 0 {
+			v.addf(key+".ids", "", "at least one id is required")
+		}
+		v.checkNotNegative(key+".days", int64(calendar.Days))
+		v.checkNotNegative(key+".max_events", int64(calendar.MaxEvents))
+	}
+
+	clear(names)
+	for i, service := range cfg.HomeAssistant.Services {
+		key := "home_assistant.services." + strconv.Itoa(i)
+		v.checkName(names, key, service.Name)
+		if service.Domain == "" {
+			v.addf(key+".domain", "", "domain is required")
+		}
+		if service.Service == "" {
+			v.addf(key+".service", "", "service is required")
+		}
+	}
+
+	clear(names)
+	for i, todo := range cfg.HomeAssistant.Todos {
+		key := "home_assistant.todos." + strconv.Itoa(i)
+		v.checkName(names, key, todo.Name)
+		if len(todo.IDs) == 0 {
+			v.addf(key+".ids", "", "at least one id is required")
+		}
+		for _, status := range todo.Status {
+			if status != string(homeassistant.TodoItemStatusNeedsAction) && status != string(homeassistant.TodoItemStatusCompleted) {
+				v.addf(key+".status", status, "invalid status %q", status)
+			}
+		}
+		v.checkNotNegative(key+".due_days", int64(todo.DueDays))
+		v.checkNotNegative(key+".max_items", int64(todo.MaxItems))
+	}
+
+	clear(names)
+	for i, source := range cfg.HTTPSources {
+		key := "http_sources." + strconv.Itoa(i)
+		v.checkName(names, key, source.Name)
+		if source.URL == "" {
+			v.addf(key+".url", "", "url is required")
+		}
+		v.checkNotNegative(key+".cache_ttl", int64(source.CacheTTL))
+	}
+
+	clear(names)
+	for i, feed := range cfg.Feeds {
+		key := "feeds." + strconv.Itoa(i)
+		v.checkName(names, key, feed.Name)
+		if feed.URL == "" {
+			v.addf(key+".url", "", "url is required")
+		}
+		v.checkNotNegative(key+".max_items", int64(feed.MaxItems))
+		v.checkNotNegative(key+".max_age", int64(feed.MaxAge))
+		v.checkNotNegative(key+".cache_ttl", int64(feed.CacheTTL))
+	}
+
+	if cfg.Prometheus != nil {
+		if cfg.Prometheus.URL == "" {
+			v.addf("prometheus.url", "", "url is required")
+		}
+		clear(names)
+		for i, query := range cfg.Prometheus.Queries {
+			key := "prometheus.queries." + strconv.Itoa(i)
+			v.checkName(names, key, query.Name)
+			if query.Query == "" {
+				v.addf(key+".query", "", "query is required")
+			}
+			v.checkNotNegative(key+".range", int64(query.Range))
+			v.checkNotNegative(key+".step", int64(query.Step))
+			v.checkNotNegative(key+".cache_ttl", int64(query.CacheTTL))
+		}
+	}
+
+	// templates can only be parsed if all files could be loaded
+	if len(v.issues) == 0 {
+		v.issues = append(v.issues, s.validateTemplates(dashboard, cfgPath)...)
+	}
+
+	return v.issues
+}
+
+// templateErrPattern matches errors like `template: base:3: unclosed action`.
+var templateErrPattern = regexp.MustCompile(`^template: [^:]*:(\d+):(?:\d+:)? ?(.*)$`)
+
+// validateTemplates parses the base & page templates of a dashboard one by one to attribute errors to their files.
+func (s *Server) validateTemplates(dashboard string, cfgPath string) []ValidationIssue {
+	base, err := s.readDashboard(dashboard)
+	if err != nil {
+		return []ValidationIssue{{File: cfgPath, Message: err.Error()}}
+	}
+
+	files := []string{base.Config.Base}
+	bodies := [][]byte{base.Body}
+	for _, page := range base.Pages {
+		files = append(files, page.Path)
+		bodies = append(bodies, page.Body)
+	}
+
+	var issues []ValidationIssue
+	for i, file := range files {
+		path := filepath.Join(s.config().DashboardDir, dashboard, file)
+		if _, err = template.New(file).Funcs(s.templateFuncs()).Parse(string(bodies[i])); err != nil {
+			issues = append(issues, templateIssue(path, bodies[i], err))
+		}
+	}
+	if len(issues) > 0 {
+		return issues
+	}
+
+	// definitions can still conflict between the files
+	if _, err = s.parseTemplates(*base); err != nil {
+		issues = append(issues, ValidationIssue{File: filepath.Join(s.config().DashboardDir, dashboard, base.Config.Base), Message: err.Error()})
+	}
+	return issues
+}
+
+// templateIssue converts a template parse error to an issue, lines are offset by the frontmatter of the file.
+func templateIssue(path string, body []byte, err error) ValidationIssue {
+	issue := ValidationIssue{
+		File:    path,
+		Message: err.Error(),
+	}
+	if match := templateErrPattern.FindStringSubmatch(err.Error()); match != nil {
+		issue.Line, _ = strconv.Atoi(match[1])
+		issue.Message = match[2]
+		if data, err := os.ReadFile(path); err == nil && bytes.HasSuffix(data, body) {
+			issue.Line += bytes.Count(data[:len(data)-len(body)], []byte("\n"))
+		}
+	}
+	return issue
+}
+
+// Validate validates the main config & the configs of all dashboards and returns all found issues.
+func (s *Server) Validate() []ValidationIssue {
+	var issues []ValidationIssue
+	if cfgPath := s.config().path; cfgPath != "" {
+		issues = append(issues, ValidateConfig(cfgPath)...)
+	}
+
+	dashboards, err := s.dashboards()
+	if err != nil {
+		return append(issues, ValidationIssue{File: s.config().DashboardDir, Message: err.Error()})
+	}
+	for _, dashboard := range dashboards {
+		issues = append(issues, s.validateDashboard(dashboard)...)
+	}
+	return issues
+}
+
+// logValidationIssues logs all issues of the main config & the dashboard configs as warnings.
+func (s *Server) logValidationIssues() {
+	for _, issue := range s.Validate() {
+		slog.Warn("invalid config", slog.String("issue", issue.String()))
+	}
+}
+
+func (s *Server) getValidate(w http.ResponseWriter, r *http.Request) {
+	s.writeValidationResponse(w, r, s.Validate())
+}
+
+func (s *Server) getDashboardValidate(w http.ResponseWriter, r *http.Request) {
+	dashboard := r.PathValue("dashboard")
+	dashboards, err := s.dashboards()
+	if err != nil {
+		Error(r.Context(), w, fmt.Sprintf("failed to list dashboards: %s", err), http.StatusInternalServerError)
+		return
+	}
+	if !slices.Contains(dashboards, dashboard) {
+		Error(r.Context(), w, "dashboard not found", http.StatusNotFound)
+		return
+	}
+
+	s.writeValidationResponse(w, r, s.validateDashboard(dashboard))
+}
+
+func (s *Server) writeValidationResponse(w http.ResponseWriter, r *http.Request, issues []ValidationIssue) {
+	if issues == nil {
+		issues = []ValidationIssue{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(ValidationResponse{
+		Valid:  len(issues) == 0,
+		Issues: issues,
+	}); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write response", slog.Any("err", err))
+	}
+}
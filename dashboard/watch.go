@@ -0,0 +1,47 @@
+package dashboard
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// WatchFile polls the file every interval and calls onChange whenever its modification time or size changed.
+// Polling is used instead of inotify since it also works for bind mounts & network filesystems. It returns when ctx is done.
+func WatchFile(ctx context.Context, path string, interval time.Duration, onChange func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := statFile(path)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := statFile(path)
+			if current == last {
+				continue
+			}
+			last = current
+			onChange()
+		}
+	}
+}
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+func statFile(path string) fileStat {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStat{}
+	}
+	return fileStat{
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		exists:  true,
+	}
+}
@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
 	"embed"
 	"flag"
+	"fmt"
+	"io"
 	"io/fs"
 	"log/slog"
 	"os"
 	"os/signal"
 	"runtime/debug"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/muesli/termenv"
@@ -19,45 +24,179 @@ import (
 //go:embed templates/*.gohtml
 var templates embed.FS
 
+const (
+	// shutdownTimeout is how long in-flight requests are drained on shutdown before they are canceled.
+	shutdownTimeout = 30 * time.Second
+	// configWatchInterval is how often the config file is checked for changes if -watch is set.
+	configWatchInterval = 2 * time.Second
+)
+
+const usage = `Usage: esphome-dashboard [command] [flags]
+
+Commands:
+  serve     Start the dashboard server (default)
+  render    Render a page of a dashboard to a file
+  validate  Validate the config, the dashboard configs & templates
+  list      List all dashboards & their pages
+
+Run 'esphome-dashboard <command> -h' to show the flags of a command.
+`
+
 func main() {
-	cfgPath := flag.String("config", "config.toml", "path to config file")
-	flag.Parse()
+	command := "serve"
+	args := os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	var code int
+	switch command {
+	case "serve":
+		code = serve(args)
+	case "render":
+		code = render(args)
+	case "validate":
+		code = validate(args)
+	case "list":
+		code = list(args)
+	case "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n%s", command, usage)
+		code = 2
+	}
+	os.Exit(code)
+}
+
+func serve(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	cfgPath := configFlag(flags)
+	watchConfig := flags.Bool("watch", false, "reload the config file when it changes")
+	_ = flags.Parse(args)
 
-	cfg, err := dashboard.LoadConfig(*cfgPath)
+	path, err := resolveConfigPath(*cfgPath)
 	if err != nil {
 		slog.Error("Error while loading config", slog.Any("err", err))
-		return
+		return 1
 	}
 
-	setupLogger(cfg.Log)
+	cfg, err := dashboard.LoadConfig(path)
+	if err != nil {
+		slog.Error("Error while loading config", slog.Any("err", err))
+		return 1
+	}
+
+	if err = setupLogger(cfg.Log, os.Stdout); err != nil {
+		slog.Error("Error while setting up logger", slog.Any("err", err))
+		return 1
+	}
+
+	version, goVersion := buildVersion()
+	slog.Info("Starting dashboard...", slog.String("version", version), slog.String("go_version", goVersion))
+	slog.Info("Config loaded", slog.Any("config", cfg))
+
+	s := dashboard.New(cfg, version, goVersion, templatesFS(cfg))
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- s.Start()
+	}()
 
+	slog.Info("Dashboard started", slog.Any("addr", cfg.ListenAddr))
+	si := make(chan os.Signal, 1)
+	signal.Notify(si, syscall.SIGINT, syscall.SIGTERM)
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+
+	changed := make(chan struct{}, 1)
+	if *watchConfig {
+		watchCtx, watchCancel := context.WithCancel(context.Background())
+		defer watchCancel()
+		go dashboard.WatchFile(watchCtx, path, configWatchInterval, func() {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		})
+	}
+
+	exitCode := 0
+loop:
+	for {
+		select {
+		case err = <-startErr:
+			slog.Error("Error while running dashboard", slog.Any("err", err))
+			exitCode = 1
+			break loop
+		case sig := <-si:
+			slog.Info("Shutting down dashboard...", slog.String("signal", sig.String()))
+			break loop
+		case <-reload:
+			slog.Info("Reloading config...", slog.String("reason", "SIGHUP"))
+			reloadConfig(s, path)
+		case <-changed:
+			slog.Info("Reloading config...", slog.String("reason", "file changed"))
+			reloadConfig(s, path)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err = s.Stop(ctx); err != nil {
+		slog.Error("Error while shutting down dashboard", slog.Any("err", err))
+		exitCode = 1
+	}
+	return exitCode
+}
+
+// configFlag registers the -config flag which is shared by all commands.
+func configFlag(flags *flag.FlagSet) *string {
+	return flags.String("config", "", "path to the TOML, YAML or JSON config file (default config.toml, config.yaml, config.yml or config.json)")
+}
+
+// resolveConfigPath returns the given config path or looks up the config file in the working directory if it's empty.
+func resolveConfigPath(cfgPath string) (string, error) {
+	if cfgPath != "" {
+		return cfgPath, nil
+	}
+	return dashboard.FindConfigFile(".", "config")
+}
+
+func buildVersion() (string, string) {
 	version := "unknown"
 	goVersion := "unknown"
 	if info, ok := debug.ReadBuildInfo(); ok {
 		version = info.Main.Version
 		goVersion = info.GoVersion
 	}
+	return version, goVersion
+}
 
-	slog.Info("Starting dashboard...", slog.String("version", version), slog.String("go_version", goVersion))
-	slog.Info("Config loaded", slog.Any("config", cfg))
-
-	var t fs.FS
+func templatesFS(cfg dashboard.Config) fs.FS {
 	if cfg.Dev {
-		t = os.DirFS(".")
-	} else {
-		t = templates
+		return os.DirFS(".")
 	}
+	return templates
+}
 
-	s := dashboard.New(cfg, version, goVersion, t)
-	go s.Start()
+func reloadConfig(s *dashboard.Server, cfgPath string) {
+	cfg, err := dashboard.LoadConfig(cfgPath)
+	if err != nil {
+		slog.Error("Error while reloading config, keeping the current config", slog.Any("err", err))
+		return
+	}
 
-	slog.Info("Dashboard started", slog.Any("addr", cfg.ListenAddr))
-	si := make(chan os.Signal, 1)
-	signal.Notify(si, syscall.SIGINT, syscall.SIGTERM)
-	<-si
+	if err = setupLogger(cfg.Log, os.Stdout); err != nil {
+		slog.Error("Error while setting up logger, keeping the current config", slog.Any("err", err))
+		return
+	}
+
+	if err = s.Reload(cfg); err != nil {
+		slog.Error("Error while applying config", slog.Any("err", err))
+	}
 }
 
-func setupLogger(cfg dashboard.LogConfig) {
+func setupLogger(cfg dashboard.LogConfig, w io.Writer) error {
 	var formatter log.Formatter
 	switch cfg.Format {
 	case dashboard.LogFormatJSON:
@@ -67,11 +206,10 @@ func setupLogger(cfg dashboard.LogConfig) {
 	case dashboard.LogFormatLogFMT:
 		formatter = log.LogfmtFormatter
 	default:
-		slog.Error("Unknown log format", slog.String("format", string(cfg.Format)))
-		os.Exit(-1)
+		return fmt.Errorf("unknown log format: %s", cfg.Format)
 	}
 
-	handler := log.NewWithOptions(os.Stdout, log.Options{
+	handler := log.NewWithOptions(w, log.Options{
 		Level:           log.Level(cfg.Level),
 		ReportTimestamp: true,
 		ReportCaller:    cfg.AddSource,
@@ -82,4 +220,5 @@ func setupLogger(cfg dashboard.LogConfig) {
 	}
 
 	slog.SetDefault(slog.New(handler))
+	return nil
 }